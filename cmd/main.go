@@ -1,13 +1,22 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
 
 	"go-fiber-boilerplate/config"
 	"go-fiber-boilerplate/database"
 	"go-fiber-boilerplate/internal/middlewares"
+	"go-fiber-boilerplate/internal/ratelimit"
+	"go-fiber-boilerplate/internal/repositories"
 	"go-fiber-boilerplate/internal/routes"
+	"go-fiber-boilerplate/internal/services"
+	"go-fiber-boilerplate/internal/storage"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/logger"
@@ -15,24 +24,193 @@ import (
 )
 
 func main() {
+	// Scaffolding a migration needs neither the configuration nor a database.
+	if len(os.Args) > 2 && os.Args[1] == "migrate" && os.Args[2] == "create" {
+		runMigrateCreate(os.Args[3:])
+		return
+	}
+
 	cfg, err := config.LoadConfig()
 	if err != nil {
 		log.Fatalf("failed to load configuration: %v", err)
 	}
 
-	database.ConnectDB(cfg)
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		runMigrate(cfg, os.Args[2:])
+		return
+	}
+
+	db := database.ConnectDB(cfg)
+
+	store, err := storage.New(cfg)
+	if err != nil {
+		log.Fatalf("failed to initialize %s storage: %v", cfg.StorageBackend, err)
+	}
+
+	replicas := database.OpenReplicas(cfg, db)
+
+	transactor := repositories.NewTransactor(db)
+	users := repositories.NewUserRepository(db, replicas)
+	samples := repositories.NewSampleRepository(db, replicas)
+	recoveryCodes := repositories.NewRecoveryCodeRepository(db)
+	pendingDeletions := repositories.NewPendingDeletionRepository(db)
+
+	rbacService := services.NewRBACService(transactor, users, repositories.NewRoleRepository(db))
+	uploadService := services.NewUploadService(cfg, repositories.NewUploadRepository(db))
+	authService := services.NewAuthService(cfg, services.AuthRepositories{
+		Transactor:         transactor,
+		Users:              users,
+		ResetTokens:        repositories.NewResetTokenRepository(db),
+		RefreshTokens:      repositories.NewRefreshTokenRepository(db),
+		RevokedTokens:      repositories.NewRevokedTokenRepository(db),
+		VerificationTokens: repositories.NewVerificationTokenRepository(db),
+		UnlockTokens:       repositories.NewUnlockTokenRepository(db),
+		LoginThrottles:     repositories.NewLoginThrottleRepository(db),
+		RecoveryCodes:      recoveryCodes,
+	})
+	svc := routes.Services{
+		Auth:    authService,
+		MFA:     services.NewMFAService(cfg, transactor, users, recoveryCodes, authService),
+		Admin:   services.NewAdminService(cfg, transactor, users, authService, samples),
+		RBAC:    rbacService,
+		Samples: services.NewSampleService(cfg, transactor, samples, pendingDeletions, store, rbacService, uploadService),
+		Uploads: uploadService,
+	}
+	reconciler := services.NewReconcileService(cfg, transactor, samples, pendingDeletions, store, uploadService)
+
+	if len(os.Args) > 1 && os.Args[1] == "reconcile" {
+		runReconcile(cfg, reconciler, os.Args[2:])
+		return
+	}
+
+	// Background workers run until shutdown, which waits for them to return.
+	workersCtx, stopWorkers := context.WithCancel(context.Background())
+	var workers sync.WaitGroup
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		replicas.Start(workersCtx, cfg.DBReplicaCheckInterval)
+	}()
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		uploadService.Start(workersCtx, cfg.TusPurgeInterval)
+	}()
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		authService.Start(workersCtx, cfg.ThrottlePurgeInterval)
+	}()
+	if cfg.AssetReconcileInterval > 0 {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			reconciler.Start(workersCtx, cfg.AssetReconcileInterval, services.ReconcileOptions{
+				Purge:  cfg.AssetReconcilePurge,
+				MinAge: cfg.AssetOrphanMinAge,
+			})
+		}()
+	}
+
+	limits, err := ratelimit.New(cfg)
+	if err != nil {
+		log.Fatalf("failed to initialize %s rate limit store: %v", cfg.RateLimitStore, err)
+	}
 
 	app := fiber.New(fiber.Config{
-		ErrorHandler:          middlewares.ErrorHandler,
-		DisableStartupMessage: true,
+		ErrorHandler:            middlewares.ErrorHandler,
+		DisableStartupMessage:   true,
+		ProxyHeader:             cfg.ProxyHeader,
+		EnableTrustedProxyCheck: len(cfg.TrustedProxies) > 0,
+		TrustedProxies:          cfg.TrustedProxies,
+		EnableIPValidation:      true,
+		// Request bodies are streamed so tus chunks up to TUS_MAX_SIZE are
+		// written straight to disk; middlewares.BodyLimit holds every other
+		// route to the default 4MB BodyLimit.
+		StreamRequestBody:            true,
+		DisablePreParseMultipartForm: true,
 	})
 
-	app.Use(logger.New())
+	app.Use(middlewares.RequestIDMiddleware())
+	app.Use(middlewares.ClientIPMiddleware(cfg))
+	app.Use(logger.New(logger.Config{
+		Format: "${time} | ${status} | ${latency} | ${locals:clientip} | ${method} | ${path} | ${locals:requestid} | ${error}\n",
+	}))
 	app.Use(recover.New())
 	app.Use(middlewares.CORSMiddleware(cfg))
 
-	routes.SetupRoutes(app, cfg)
+	routes.SetupRoutes(app, cfg, svc, limits)
+
+	signals, stopSignals := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stopSignals()
 
 	fmt.Printf("  ➜  [API] Local:   http://localhost:%s\n", cfg.Port)
-	log.Fatal(app.Listen("0.0.0.0:" + cfg.Port))
+	listenErr := make(chan error, 1)
+	go func() {
+		listenErr <- app.Listen("0.0.0.0:" + cfg.Port)
+	}()
+
+	select {
+	case err = <-listenErr:
+		// The server could not start; clean up and exit with its error below.
+	case <-signals.Done():
+		// A second signal kills the process without waiting.
+		stopSignals()
+		log.Printf("Shutting down, waiting up to %s for requests and background tasks", cfg.ShutdownTimeout)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+
+	// The workers do not serve requests, so they are stopped right away and
+	// return while requests drain.
+	stopWorkers()
+
+	// Stop accepting connections and let in-flight requests finish, so none
+	// is cut off halfway, e.g. between deleting a sample's rows and its images.
+	if err == nil {
+		if shutdownErr := app.ShutdownWithContext(ctx); shutdownErr != nil {
+			log.Printf("warning: dropped requests still running at the shutdown timeout: %v", shutdownErr)
+		}
+	}
+
+	// Let the workers and the emails queued by requests finish before the
+	// connections they use are closed.
+	if ctx.Err() == nil && !waitUntil(ctx, workers.Wait, authService.Wait) {
+		log.Printf("warning: background tasks still running at the shutdown timeout")
+	}
+
+	if closeErr := limits.Close(); closeErr != nil {
+		log.Printf("failed to close rate limit store: %v", closeErr)
+	}
+	if closeErr := replicas.Close(); closeErr != nil {
+		log.Printf("failed to close read replicas: %v", closeErr)
+	}
+	if closeErr := database.CloseDB(db); closeErr != nil {
+		log.Printf("failed to close database: %v", closeErr)
+	}
+
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Println("Server stopped")
+}
+
+// waitUntil runs the wait functions in turn and reports whether they all
+// returned before ctx was done.
+func waitUntil(ctx context.Context, waits ...func()) bool {
+	done := make(chan struct{})
+	go func() {
+		for _, wait := range waits {
+			wait()
+		}
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return true
+	case <-ctx.Done():
+		return false
+	}
 }
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"go-fiber-boilerplate/config"
+	"go-fiber-boilerplate/database"
+)
+
+const migrateUsage = `usage: migrate <command>
+
+  up                     apply every pending migration
+  down [-steps 1]        roll back the last applied migrations
+  status                 list migrations and when they were applied
+  create [-dir D] NAME   write empty up and down files for a new migration,
+                         for Postgres in D and for SQLite in D/sqlite`
+
+// runMigrateCreate implements "migrate create". It runs before the
+// configuration is loaded, so it works without a .env or a database.
+func runMigrateCreate(args []string) {
+	flags := flag.NewFlagSet("migrate create", flag.ExitOnError)
+	dir := flags.String("dir", "database/migrations", "directory to write the migration files to")
+	flags.Parse(args)
+
+	paths, err := database.CreateMigration(*dir, strings.Join(flags.Args(), "_"))
+	if err != nil {
+		log.Fatalf("failed to create migration: %v", err)
+	}
+	for _, path := range paths {
+		fmt.Println(path)
+	}
+}
+
+// runMigrate implements "migrate up|down|status"; create is dispatched to
+// runMigrateCreate before the configuration is loaded.
+func runMigrate(cfg *config.Config, args []string) {
+	if len(args) == 0 {
+		log.Fatal(migrateUsage)
+	}
+
+	migrator, err := database.NewMigrator(database.OpenDB(cfg))
+	if err != nil {
+		log.Fatalf("failed to load migrations: %v", err)
+	}
+	ctx := context.Background()
+
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		for _, migration := range applied {
+			fmt.Printf("applied %d_%s\n", migration.Version, migration.Name)
+		}
+		if err != nil {
+			log.Fatalf("migration failed: %v", err)
+		}
+		if len(applied) == 0 {
+			fmt.Println("no pending migrations")
+		}
+	case "down":
+		flags := flag.NewFlagSet("migrate down", flag.ExitOnError)
+		steps := flags.Int("steps", 1, "number of migrations to roll back")
+		flags.Parse(args[1:])
+
+		rolledBack, err := migrator.Down(ctx, *steps)
+		for _, migration := range rolledBack {
+			fmt.Printf("rolled back %d_%s\n", migration.Version, migration.Name)
+		}
+		if err != nil {
+			log.Fatalf("rollback failed: %v", err)
+		}
+		if len(rolledBack) == 0 {
+			fmt.Println("no applied migrations")
+		}
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			log.Fatalf("failed to read migration status: %v", err)
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+		for _, status := range statuses {
+			appliedAt := "pending"
+			if status.AppliedAt != nil {
+				appliedAt = status.AppliedAt.Format(time.RFC3339)
+			}
+			name := status.Name
+			if status.Missing {
+				name = "(file missing)"
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\n", status.Version, name, appliedAt)
+		}
+		w.Flush()
+	default:
+		log.Fatal(migrateUsage)
+	}
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+
+	"go-fiber-boilerplate/config"
+	"go-fiber-boilerplate/internal/services"
+)
+
+// runReconcile implements "reconcile [-purge] [-min-age 1h]": it runs the
+// orphaned asset reconciliation once and prints the report as JSON.
+func runReconcile(cfg *config.Config, reconciler *services.ReconcileService, args []string) {
+	flags := flag.NewFlagSet("reconcile", flag.ExitOnError)
+	purge := flags.Bool("purge", cfg.AssetReconcilePurge, "delete orphaned assets instead of only reporting them")
+	minAge := flags.Duration("min-age", cfg.AssetOrphanMinAge, "ignore assets younger than this")
+	flags.Parse(args)
+
+	report, err := reconciler.Run(context.Background(), services.ReconcileOptions{
+		Purge:  *purge,
+		MinAge: *minAge,
+	})
+	if err != nil {
+		log.Fatalf("asset reconciliation failed: %v", err)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		log.Fatalf("failed to write report: %v", err)
+	}
+}
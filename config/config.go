@@ -4,36 +4,109 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"net/http"
+	"net/netip"
+	"net/url"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
 type Config struct {
-	DBHost              string
-	DBPort              string
-	DBUser              string
-	DBPassword          string
-	DBName              string
-	Port                string
-	JWTSecret           string
-	JWTIssuer           string
-	JWTAudience         string
-	ResetTokenSecret    string
-	AllowedOrigins      string
-	AllowCredentials    bool
-	SMTPHost            string
-	SMTPPort            string
-	SMTPUsername        string
-	SMTPPassword        string
-	FromEmail           string
-	FrontendURL         string
-	CloudinaryCloudName string
-	CloudinaryAPIKey    string
-	CloudinaryAPISecret string
+	DBDriver                 string
+	DBSQLitePath             string
+	DatabaseURL              string
+	DBHost                   string
+	DBPort                   string
+	DBUser                   string
+	DBPassword               string
+	DBName                   string
+	DBSSLMode                string
+	DBSSLRootCert            string
+	DBTimeZone               string
+	DBMaxOpenConns           int
+	DBMaxIdleConns           int
+	DBConnMaxLifetime        time.Duration
+	DBConnMaxIdleTime        time.Duration
+	DBConnectAttempts        int
+	DBConnectBackoff         time.Duration
+	DBReadReplicaURLs        []string
+	DBReplicaCheckInterval   time.Duration
+	DBReplicaMaxLag          time.Duration
+	DBAutoMigrate            bool
+	Port                     string
+	ShutdownTimeout          time.Duration
+	JWTSecret                string
+	JWTIssuer                string
+	JWTAudience              string
+	AccessTokenTTL           time.Duration
+	RefreshTokenTTL          time.Duration
+	RequireEmailVerification bool
+	LoginLockoutThreshold    int
+	LoginLockoutDuration     time.Duration
+	LoginBackoffBase         time.Duration
+	ThrottlePurgeInterval    time.Duration
+	MFAIssuer                string
+	MFAEncryptionKey         string
+	BootstrapAdminEmail      string
+	ResetTokenSecret         string
+	AllowedOrigins           string
+	AllowCredentials         bool
+	TrustedProxies           []string
+	ProxyHeader              string
+	SMTPHost                 string
+	SMTPPort                 string
+	SMTPUsername             string
+	SMTPPassword             string
+	FromEmail                string
+	FrontendURL              string
+	StorageBackend           string
+	StorageLocalDir          string
+	StoragePublicURL         string
+	CloudinaryCloudName      string
+	CloudinaryAPIKey         string
+	CloudinaryAPISecret      string
+	S3Endpoint               string
+	S3Region                 string
+	S3Bucket                 string
+	S3AccessKey              string
+	S3SecretKey              string
+	S3UseSSL                 bool
+	TusUploadDir             string
+	TusMaxSize               int64
+	TusUploadTTL             time.Duration
+	TusPurgeInterval         time.Duration
+	AssetReconcileInterval   time.Duration
+	AssetReconcilePurge      bool
+	AssetOrphanMinAge        time.Duration
+	RateLimitStore           string
+	RateLimitMaxKeys         int
+	RateLimitSweepInterval   time.Duration
+	RedisURL                 string
 }
 
+// Database drivers selectable with DB_DRIVER.
+const (
+	DBDriverPostgres = "postgres"
+	DBDriverSQLite   = "sqlite"
+)
+
+// Storage backends selectable with STORAGE_BACKEND.
+const (
+	StorageCloudinary = "cloudinary"
+	StorageLocal      = "local"
+	StorageS3         = "s3"
+)
+
+// Rate limit stores selectable with RATE_LIMIT_STORE.
+const (
+	RateLimitStoreMemory = "memory"
+	RateLimitStoreRedis  = "redis"
+)
+
 func LoadConfig() (*Config, error) {
 	if err := godotenv.Load(); err != nil {
 		log.Println("No .env file found, using environment variables")
@@ -42,29 +115,29 @@ func LoadConfig() (*Config, error) {
 	var err error
 	cfg := &Config{}
 
-	if cfg.DBHost, err = getRequiredEnv("DB_HOST"); err != nil {
+	if err := loadDatabaseConfig(cfg); err != nil {
 		return nil, err
 	}
-	if cfg.DBPort, err = getRequiredEnv("DB_PORT"); err != nil {
+	if cfg.DBAutoMigrate, err = getBoolEnv("DB_AUTO_MIGRATE", true); err != nil {
 		return nil, err
 	}
-	if cfg.DBUser, err = getRequiredEnv("DB_USER"); err != nil {
+	if cfg.Port, err = getRequiredEnv("PORT"); err != nil {
 		return nil, err
 	}
-	if cfg.DBPassword, err = getRequiredEnv("DB_PASSWORD"); err != nil {
+	if cfg.ShutdownTimeout, err = getDurationEnv("SHUTDOWN_TIMEOUT", 20*time.Second); err != nil {
 		return nil, err
 	}
-	if cfg.DBName, err = getRequiredEnv("DB_NAME"); err != nil {
+	if cfg.JWTSecret, err = getRequiredEnv("JWT_SECRET"); err != nil {
 		return nil, err
 	}
-	if cfg.Port, err = getRequiredEnv("PORT"); err != nil {
+	cfg.JWTIssuer = os.Getenv("JWT_ISSUER")
+	cfg.JWTAudience = os.Getenv("JWT_AUDIENCE")
+	if cfg.AccessTokenTTL, err = getDurationEnv("JWT_ACCESS_TTL", 15*time.Minute); err != nil {
 		return nil, err
 	}
-	if cfg.JWTSecret, err = getRequiredEnv("JWT_SECRET"); err != nil {
+	if cfg.RefreshTokenTTL, err = getDurationEnv("REFRESH_TOKEN_TTL", 7*24*time.Hour); err != nil {
 		return nil, err
 	}
-	cfg.JWTIssuer = os.Getenv("JWT_ISSUER")
-	cfg.JWTAudience = os.Getenv("JWT_AUDIENCE")
 	if cfg.ResetTokenSecret, err = getRequiredEnv("RESET_TOKEN_SECRET"); err != nil {
 		return nil, err
 	}
@@ -84,6 +157,10 @@ func LoadConfig() (*Config, error) {
 		return nil, fmt.Errorf("CORS_ALLOW_CREDENTIALS must be 'true' or 'false'")
 	}
 
+	if err := loadProxyConfig(cfg); err != nil {
+		return nil, err
+	}
+
 	if cfg.SMTPHost, err = getRequiredEnv("SMTP_HOST"); err != nil {
 		return nil, err
 	}
@@ -102,13 +179,57 @@ func LoadConfig() (*Config, error) {
 	if cfg.FrontendURL, err = getRequiredEnv("FRONTEND_URL"); err != nil {
 		return nil, err
 	}
-	if cfg.CloudinaryCloudName, err = getRequiredEnv("CLOUDINARY_CLOUD_NAME"); err != nil {
+	if cfg.RequireEmailVerification, err = getBoolEnv("REQUIRE_EMAIL_VERIFICATION", false); err != nil {
+		return nil, err
+	}
+	lockoutThreshold, err := getInt64Env("LOGIN_LOCKOUT_THRESHOLD", 10)
+	if err != nil {
+		return nil, err
+	}
+	cfg.LoginLockoutThreshold = int(lockoutThreshold)
+	if cfg.LoginLockoutDuration, err = getDurationEnv("LOGIN_LOCKOUT_DURATION", 30*time.Minute); err != nil {
 		return nil, err
 	}
-	if cfg.CloudinaryAPIKey, err = getRequiredEnv("CLOUDINARY_API_KEY"); err != nil {
+	if cfg.LoginBackoffBase, err = getDurationEnv("LOGIN_BACKOFF_BASE", time.Second); err != nil {
 		return nil, err
 	}
-	if cfg.CloudinaryAPISecret, err = getRequiredEnv("CLOUDINARY_API_SECRET"); err != nil {
+	if cfg.ThrottlePurgeInterval, err = getDurationEnv("LOGIN_THROTTLE_PURGE_INTERVAL", time.Hour); err != nil {
+		return nil, err
+	}
+	if cfg.MFAIssuer = os.Getenv("MFA_ISSUER"); cfg.MFAIssuer == "" {
+		cfg.MFAIssuer = "Go Fiber Boilerplate"
+	}
+	if cfg.MFAEncryptionKey, err = getRequiredEnv("MFA_ENCRYPTION_KEY"); err != nil {
+		return nil, err
+	}
+	cfg.BootstrapAdminEmail = os.Getenv("BOOTSTRAP_ADMIN_EMAIL")
+	if err := loadStorageConfig(cfg); err != nil {
+		return nil, err
+	}
+	if cfg.TusUploadDir = os.Getenv("TUS_UPLOAD_DIR"); cfg.TusUploadDir == "" {
+		cfg.TusUploadDir = "./tmp/tus"
+	}
+	if cfg.TusMaxSize, err = getInt64Env("TUS_MAX_SIZE", 50*1024*1024); err != nil {
+		return nil, err
+	}
+	if cfg.TusUploadTTL, err = getDurationEnv("TUS_UPLOAD_TTL", 24*time.Hour); err != nil {
+		return nil, err
+	}
+	if cfg.TusPurgeInterval, err = getDurationEnv("TUS_PURGE_INTERVAL", time.Hour); err != nil {
+		return nil, err
+	}
+	// The reconciliation job only runs in the background when an interval is
+	// set; it can always be run by hand with "reconcile".
+	if cfg.AssetReconcileInterval, err = getDurationEnv("ASSET_RECONCILE_INTERVAL", 0); err != nil {
+		return nil, err
+	}
+	if cfg.AssetReconcilePurge, err = getBoolEnv("ASSET_RECONCILE_PURGE", false); err != nil {
+		return nil, err
+	}
+	if cfg.AssetOrphanMinAge, err = getDurationEnv("ASSET_ORPHAN_MIN_AGE", time.Hour); err != nil {
+		return nil, err
+	}
+	if err := loadRateLimitConfig(cfg); err != nil {
 		return nil, err
 	}
 
@@ -119,6 +240,247 @@ func LoadConfig() (*Config, error) {
 	return cfg, nil
 }
 
+// loadDatabaseConfig reads the driver chosen with DB_DRIVER and its
+// connection settings, plus the pool and read replica settings.
+func loadDatabaseConfig(cfg *Config) error {
+	var err error
+
+	if cfg.DBDriver = strings.ToLower(os.Getenv("DB_DRIVER")); cfg.DBDriver == "" {
+		cfg.DBDriver = DBDriverPostgres
+	}
+	switch cfg.DBDriver {
+	case DBDriverPostgres:
+		if err := loadPostgresConfig(cfg); err != nil {
+			return err
+		}
+	case DBDriverSQLite:
+		if cfg.DBSQLitePath = os.Getenv("DB_SQLITE_PATH"); cfg.DBSQLitePath == "" {
+			cfg.DBSQLitePath = "go_fiber.db"
+		}
+	default:
+		return fmt.Errorf("DB_DRIVER must be one of %s or %s", DBDriverPostgres, DBDriverSQLite)
+	}
+
+	maxOpen, err := getInt64Env("DB_MAX_OPEN_CONNS", 25)
+	if err != nil {
+		return err
+	}
+	maxIdle, err := getInt64Env("DB_MAX_IDLE_CONNS", 5)
+	if err != nil {
+		return err
+	}
+	if maxIdle > maxOpen {
+		return errors.New("DB_MAX_IDLE_CONNS must not exceed DB_MAX_OPEN_CONNS")
+	}
+	cfg.DBMaxOpenConns, cfg.DBMaxIdleConns = int(maxOpen), int(maxIdle)
+	if cfg.DBConnMaxLifetime, err = getDurationEnv("DB_CONN_MAX_LIFETIME", 30*time.Minute); err != nil {
+		return err
+	}
+	if cfg.DBConnMaxIdleTime, err = getDurationEnv("DB_CONN_MAX_IDLE_TIME", 5*time.Minute); err != nil {
+		return err
+	}
+
+	attempts, err := getInt64Env("DB_CONNECT_ATTEMPTS", 5)
+	if err != nil {
+		return err
+	}
+	cfg.DBConnectAttempts = int(attempts)
+	if cfg.DBConnectBackoff, err = getDurationEnv("DB_CONNECT_BACKOFF", time.Second); err != nil {
+		return err
+	}
+
+	for _, replicaURL := range strings.Split(os.Getenv("DB_READ_REPLICA_URLS"), ",") {
+		if replicaURL = strings.TrimSpace(replicaURL); replicaURL == "" {
+			continue
+		}
+		if cfg.DBDriver != DBDriverPostgres {
+			return errors.New("DB_READ_REPLICA_URLS requires DB_DRIVER=postgres")
+		}
+		if !isPostgresURL(replicaURL) {
+			return errors.New("DB_READ_REPLICA_URLS must be a comma-separated list of postgres:// URLs")
+		}
+		cfg.DBReadReplicaURLs = append(cfg.DBReadReplicaURLs, replicaURL)
+	}
+	if cfg.DBReplicaCheckInterval, err = getDurationEnv("DB_REPLICA_CHECK_INTERVAL", 10*time.Second); err != nil {
+		return err
+	}
+	if cfg.DBReplicaMaxLag, err = getDurationEnv("DB_REPLICA_MAX_LAG", 30*time.Second); err != nil {
+		return err
+	}
+	return nil
+}
+
+// loadPostgresConfig reads the connection either from DATABASE_URL or from
+// the DB_HOST group of variables, plus the TLS and session settings.
+// DB_SSLMODE, DB_SSLROOTCERT and DB_TIMEZONE override the URL's parameters
+// when set; without a URL they default to the previous hardcoded values.
+func loadPostgresConfig(cfg *Config) error {
+	var err error
+
+	if cfg.DatabaseURL = os.Getenv("DATABASE_URL"); cfg.DatabaseURL != "" {
+		if !isPostgresURL(cfg.DatabaseURL) {
+			return errors.New("DATABASE_URL must be a postgres:// or postgresql:// URL")
+		}
+	} else {
+		if cfg.DBHost, err = getRequiredEnv("DB_HOST"); err != nil {
+			return err
+		}
+		if cfg.DBPort, err = getRequiredEnv("DB_PORT"); err != nil {
+			return err
+		}
+		if cfg.DBUser, err = getRequiredEnv("DB_USER"); err != nil {
+			return err
+		}
+		if cfg.DBPassword, err = getRequiredEnv("DB_PASSWORD"); err != nil {
+			return err
+		}
+		if cfg.DBName, err = getRequiredEnv("DB_NAME"); err != nil {
+			return err
+		}
+		cfg.DBSSLMode = "disable"
+		cfg.DBTimeZone = "Asia/Jakarta"
+	}
+
+	if sslMode := strings.ToLower(os.Getenv("DB_SSLMODE")); sslMode != "" {
+		switch sslMode {
+		case "disable", "allow", "prefer", "require", "verify-ca", "verify-full":
+			cfg.DBSSLMode = sslMode
+		default:
+			return errors.New("DB_SSLMODE must be one of disable, allow, prefer, require, verify-ca or verify-full")
+		}
+	}
+	if cfg.DBSSLRootCert = os.Getenv("DB_SSLROOTCERT"); cfg.DBSSLRootCert != "" {
+		if _, err := os.Stat(cfg.DBSSLRootCert); err != nil {
+			return fmt.Errorf("DB_SSLROOTCERT: %w", err)
+		}
+	}
+	if timeZone := os.Getenv("DB_TIMEZONE"); timeZone != "" {
+		if _, err := time.LoadLocation(timeZone); err != nil {
+			return fmt.Errorf("DB_TIMEZONE: unknown time zone %q", timeZone)
+		}
+		cfg.DBTimeZone = timeZone
+	}
+	return nil
+}
+
+func isPostgresURL(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	return err == nil && (u.Scheme == "postgres" || u.Scheme == "postgresql")
+}
+
+func loadStorageConfig(cfg *Config) error {
+	var err error
+
+	if cfg.StorageBackend = strings.ToLower(os.Getenv("STORAGE_BACKEND")); cfg.StorageBackend == "" {
+		cfg.StorageBackend = StorageCloudinary
+	}
+	cfg.StoragePublicURL = strings.TrimSuffix(os.Getenv("STORAGE_PUBLIC_URL"), "/")
+
+	switch cfg.StorageBackend {
+	case StorageCloudinary:
+		if cfg.CloudinaryCloudName, err = getRequiredEnv("CLOUDINARY_CLOUD_NAME"); err != nil {
+			return err
+		}
+		if cfg.CloudinaryAPIKey, err = getRequiredEnv("CLOUDINARY_API_KEY"); err != nil {
+			return err
+		}
+		if cfg.CloudinaryAPISecret, err = getRequiredEnv("CLOUDINARY_API_SECRET"); err != nil {
+			return err
+		}
+	case StorageLocal:
+		if cfg.StorageLocalDir = os.Getenv("STORAGE_LOCAL_DIR"); cfg.StorageLocalDir == "" {
+			cfg.StorageLocalDir = "./uploads"
+		}
+		if cfg.StoragePublicURL == "" {
+			cfg.StoragePublicURL = "http://localhost:" + cfg.Port + "/uploads"
+		}
+	case StorageS3:
+		if cfg.S3Endpoint, err = getRequiredEnv("S3_ENDPOINT"); err != nil {
+			return err
+		}
+		if cfg.S3Bucket, err = getRequiredEnv("S3_BUCKET"); err != nil {
+			return err
+		}
+		if cfg.S3AccessKey, err = getRequiredEnv("S3_ACCESS_KEY"); err != nil {
+			return err
+		}
+		if cfg.S3SecretKey, err = getRequiredEnv("S3_SECRET_KEY"); err != nil {
+			return err
+		}
+		cfg.S3Region = os.Getenv("S3_REGION")
+		if cfg.S3UseSSL, err = getBoolEnv("S3_USE_SSL", true); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("STORAGE_BACKEND must be one of %s, %s or %s", StorageCloudinary, StorageLocal, StorageS3)
+	}
+
+	return nil
+}
+
+// loadProxyConfig reads the proxies whose PROXY_HEADER is believed. Without
+// trusted proxies the header is ignored, since any client could set it.
+func loadProxyConfig(cfg *Config) error {
+	for _, proxy := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		if proxy = strings.TrimSpace(proxy); proxy == "" {
+			continue
+		}
+		if _, err := ParseProxyPrefix(proxy); err != nil {
+			return fmt.Errorf("TRUSTED_PROXIES: %q is not an IP address or CIDR", proxy)
+		}
+		cfg.TrustedProxies = append(cfg.TrustedProxies, proxy)
+	}
+
+	cfg.ProxyHeader = http.CanonicalHeaderKey(strings.TrimSpace(os.Getenv("PROXY_HEADER")))
+	if cfg.ProxyHeader != "" && len(cfg.TrustedProxies) == 0 {
+		return errors.New("PROXY_HEADER requires TRUSTED_PROXIES")
+	}
+	return nil
+}
+
+// ParseProxyPrefix parses a TRUSTED_PROXIES entry, either a CIDR or a single
+// address.
+func ParseProxyPrefix(proxy string) (netip.Prefix, error) {
+	if strings.Contains(proxy, "/") {
+		prefix, err := netip.ParsePrefix(proxy)
+		if err != nil {
+			return netip.Prefix{}, err
+		}
+		return prefix.Masked(), nil
+	}
+	addr, err := netip.ParseAddr(proxy)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	addr = addr.Unmap()
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
+
+func loadRateLimitConfig(cfg *Config) error {
+	if cfg.RateLimitStore = strings.ToLower(os.Getenv("RATE_LIMIT_STORE")); cfg.RateLimitStore == "" {
+		cfg.RateLimitStore = RateLimitStoreMemory
+	}
+
+	switch cfg.RateLimitStore {
+	case RateLimitStoreMemory:
+		maxKeys, err := getInt64Env("RATE_LIMIT_MAX_KEYS", 100000)
+		if err != nil {
+			return err
+		}
+		cfg.RateLimitMaxKeys = int(maxKeys)
+		if cfg.RateLimitSweepInterval, err = getDurationEnv("RATE_LIMIT_SWEEP_INTERVAL", time.Minute); err != nil {
+			return err
+		}
+	case RateLimitStoreRedis:
+		if cfg.RedisURL = os.Getenv("REDIS_URL"); cfg.RedisURL == "" {
+			cfg.RedisURL = "redis://localhost:6379/0"
+		}
+	default:
+		return fmt.Errorf("RATE_LIMIT_STORE must be one of %s or %s", RateLimitStoreMemory, RateLimitStoreRedis)
+	}
+	return nil
+}
+
 func (c *Config) validate() error {
 	if c.JWTSecret == "default_secret" {
 		return errors.New("JWT_SECRET must not use insecure default")
@@ -126,6 +488,12 @@ func (c *Config) validate() error {
 	if c.ResetTokenSecret == c.JWTSecret {
 		return errors.New("RESET_TOKEN_SECRET must differ from JWT_SECRET")
 	}
+	if c.MFAEncryptionKey == c.JWTSecret || c.MFAEncryptionKey == c.ResetTokenSecret {
+		return errors.New("MFA_ENCRYPTION_KEY must differ from JWT_SECRET and RESET_TOKEN_SECRET")
+	}
+	if c.RefreshTokenTTL <= c.AccessTokenTTL {
+		return errors.New("REFRESH_TOKEN_TTL must be longer than JWT_ACCESS_TTL")
+	}
 	if c.AllowCredentials && c.AllowedOrigins == "*" {
 		return errors.New("CORS_ALLOWED_ORIGINS cannot be '*' when credentials are allowed")
 	}
@@ -138,3 +506,40 @@ func getRequiredEnv(key string) (string, error) {
 	}
 	return "", fmt.Errorf("%s environment variable is required", key)
 }
+
+func getDurationEnv(key string, fallback time.Duration) (time.Duration, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("%s must be a positive duration (e.g. 15m, 24h)", key)
+	}
+	return d, nil
+}
+
+func getInt64Env(key string, fallback int64) (int64, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback, nil
+	}
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("%s must be a positive integer", key)
+	}
+	return n, nil
+}
+
+func getBoolEnv(key string, fallback bool) (bool, error) {
+	switch strings.ToLower(os.Getenv(key)) {
+	case "":
+		return fallback, nil
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	default:
+		return false, fmt.Errorf("%s must be 'true' or 'false'", key)
+	}
+}
@@ -1,47 +1,226 @@
 package database
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
 	"log"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"time"
 
 	"go-fiber-boilerplate/config"
-	"go-fiber-boilerplate/internal/models"
 
+	"github.com/glebarez/sqlite"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 )
 
-var DB *gorm.DB
+// ConnectDB opens the connection and prepares the schema for the server.
+func ConnectDB(cfg *config.Config) *gorm.DB {
+	db := OpenDB(cfg)
 
-func ConnectDB(cfg *config.Config) {
-	dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=disable TimeZone=Asia/Jakarta",
-		cfg.DBHost, cfg.DBUser, cfg.DBPassword, cfg.DBName, cfg.DBPort)
+	if err := prepareSchema(db, cfg); err != nil {
+		log.Fatal(err)
+	}
+	return db
+}
 
-	var err error
-	DB, err = gorm.Open(postgres.Open(dsn), &gorm.Config{
-		Logger: logger.Default.LogMode(logger.Silent),
-	})
+// maxConnectBackoff caps the doubling wait between connection attempts.
+const maxConnectBackoff = 30 * time.Second
 
+// OpenDB only opens the connection to the DB_DRIVER database, for commands
+// that manage the schema themselves. While the database is unreachable it retries
+// DB_CONNECT_ATTEMPTS times, waiting DB_CONNECT_BACKOFF doubling per attempt,
+// so the app survives starting before its database.
+func OpenDB(cfg *config.Config) *gorm.DB {
+	dsn, err := dataSourceName(cfg)
 	if err != nil {
-		log.Fatal("Failed to connect to database:", err)
+		log.Fatal("Invalid database configuration:", err)
+	}
+
+	var db *gorm.DB
+	backoff := cfg.DBConnectBackoff
+	for attempt := 1; ; attempt++ {
+		db, err = gorm.Open(dialector(cfg, dsn), &gorm.Config{
+			Logger: logger.Default.LogMode(logger.Silent),
+		})
+		if err == nil {
+			break
+		}
+		if attempt >= cfg.DBConnectAttempts {
+			log.Fatal("Failed to connect to database:", err)
+		}
+
+		log.Printf("Database not reachable (attempt %d/%d), retrying in %s: %v", attempt, cfg.DBConnectAttempts, backoff, err)
+		time.Sleep(backoff)
+		backoff = min(backoff*2, maxConnectBackoff)
+	}
+
+	if err := configurePool(db, cfg); err != nil {
+		log.Fatal("Failed to configure database pool:", err)
 	}
 
 	log.Println("Database connected successfully")
+	return db
+}
+
+// CloseDB closes the connection pool opened by ConnectDB or OpenDB.
+func CloseDB(db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Close()
+}
+
+// configurePool applies the DB_MAX_OPEN_CONNS group of settings.
+func configurePool(db *gorm.DB, cfg *config.Config) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	sqlDB.SetMaxOpenConns(cfg.DBMaxOpenConns)
+	sqlDB.SetMaxIdleConns(cfg.DBMaxIdleConns)
+	sqlDB.SetConnMaxLifetime(cfg.DBConnMaxLifetime)
+	sqlDB.SetConnMaxIdleTime(cfg.DBConnMaxIdleTime)
+	if cfg.DBDriver == config.DBDriverSQLite && cfg.DBSQLitePath == sqliteMemory {
+		// An in-memory database is gone once its last connection closes.
+		sqlDB.SetMaxIdleConns(max(cfg.DBMaxIdleConns, 1))
+		sqlDB.SetConnMaxLifetime(0)
+		sqlDB.SetConnMaxIdleTime(0)
+	}
+	return nil
+}
+
+func dialector(cfg *config.Config, dsn string) gorm.Dialector {
+	if cfg.DBDriver == config.DBDriverSQLite {
+		return sqlite.Open(dsn)
+	}
+	return postgres.Open(dsn)
+}
 
-	err = DB.AutoMigrate(
-		&models.User{},
-		&models.Sample{},
-		&models.PasswordResetToken{},
-	)
+// dataSourceName builds the connection string from DATABASE_URL or the
+// DB_HOST group, with DB_SSLMODE, DB_SSLROOTCERT and DB_TIMEZONE applied, or
+// from DB_SQLITE_PATH.
+func dataSourceName(cfg *config.Config) (string, error) {
+	if cfg.DBDriver == config.DBDriverSQLite {
+		return sqliteDataSourceName(cfg.DBSQLitePath), nil
+	}
+	if cfg.DatabaseURL != "" {
+		dsn, err := urlDataSourceName(cfg, cfg.DatabaseURL)
+		if err != nil {
+			return "", fmt.Errorf("DATABASE_URL: %w", err)
+		}
+		return dsn, nil
+	}
+
+	params := []string{
+		"host", cfg.DBHost,
+		"port", cfg.DBPort,
+		"user", cfg.DBUser,
+		"password", cfg.DBPassword,
+		"dbname", cfg.DBName,
+		"sslmode", cfg.DBSSLMode,
+		"sslrootcert", cfg.DBSSLRootCert,
+		"TimeZone", cfg.DBTimeZone,
+	}
+	var dsn []string
+	for i := 0; i < len(params); i += 2 {
+		if params[i+1] != "" {
+			dsn = append(dsn, params[i]+"="+quoteDSNValue(params[i+1]))
+		}
+	}
+	return strings.Join(dsn, " "), nil
+}
 
+// urlDataSourceName applies DB_SSLMODE, DB_SSLROOTCERT and DB_TIMEZONE to a
+// postgres:// URL.
+func urlDataSourceName(cfg *config.Config, rawURL string) (string, error) {
+	u, err := url.Parse(rawURL)
 	if err != nil {
-		log.Fatal("Failed to migrate database:", err)
+		return "", err
 	}
+	query := u.Query()
+	for key, value := range map[string]string{
+		"sslmode":     cfg.DBSSLMode,
+		"sslrootcert": cfg.DBSSLRootCert,
+		"TimeZone":    cfg.DBTimeZone,
+	} {
+		if value != "" {
+			query.Set(key, value)
+		}
+	}
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
+
+// sqliteMemory is the DB_SQLITE_PATH of a database that is never written to
+// disk.
+const sqliteMemory = ":memory:"
 
-	log.Println("Database migration completed")
+// memoryDatabases numbers the in-memory SQLite databases, so every OpenDB
+// gets its own.
+var memoryDatabases atomic.Uint64
+
+// sqliteDataSourceName adds the options the app relies on to the SQLite
+// path: foreign keys are enforced, and writers wait for each other instead of
+// failing, taking the write lock when their transaction begins. An in-memory
+// database is shared by the connections of one pool.
+func sqliteDataSourceName(path string) string {
+	options := "_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_txlock=immediate"
+	if path == sqliteMemory {
+		return fmt.Sprintf("file:/go-fiber-%d?vfs=memdb&%s", memoryDatabases.Add(1), options)
+	}
+
+	separator := "?"
+	if strings.Contains(path, "?") {
+		separator = "&"
+	}
+	return path + separator + "_pragma=journal_mode(WAL)&" + options
 }
 
-func GetDB() *gorm.DB {
-	return DB
+// quoteDSNValue quotes a key/value connection string value so passwords
+// with spaces or quotes survive.
+func quoteDSNValue(value string) string {
+	value = strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value)
+	return "'" + value + "'"
+}
+
+// prepareSchema applies pending migrations, unless DB_AUTO_MIGRATE is off,
+// and then seeds reference data. Both run under the migration lock so
+// replicas starting together do not race. Seeding needs the current schema,
+// so it is skipped while migrations are still pending.
+func prepareSchema(db *gorm.DB, cfg *config.Config) error {
+	migrator, err := NewMigrator(db)
+	if err != nil {
+		return fmt.Errorf("failed to load migrations: %w", err)
+	}
+
+	ctx := context.Background()
+	return migrator.WithLock(ctx, func(conn *sql.Conn) error {
+		if cfg.DBAutoMigrate {
+			applied, err := migrator.up(ctx, conn)
+			if err != nil {
+				return fmt.Errorf("failed to migrate database: %w", err)
+			}
+			log.Printf("Database migration completed (%d applied)", len(applied))
+		}
+
+		pending, err := migrator.pending(ctx, conn)
+		if err != nil {
+			return fmt.Errorf("failed to read migration status: %w", err)
+		}
+		if len(pending) > 0 {
+			log.Printf("warning: %d migrations pending, skipping role seeding; run \"migrate up\"", len(pending))
+			return nil
+		}
+
+		if err := SeedRoles(db, cfg.BootstrapAdminEmail); err != nil {
+			return fmt.Errorf("failed to seed roles: %w", err)
+		}
+		return nil
+	})
 }
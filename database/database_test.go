@@ -0,0 +1,215 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go-fiber-boilerplate/config"
+	"go-fiber-boilerplate/internal/models"
+
+	"gorm.io/gorm"
+)
+
+func sqliteConfig() *config.Config {
+	return &config.Config{
+		DBDriver:          config.DBDriverSQLite,
+		DBSQLitePath:      sqliteMemory,
+		DBConnectAttempts: 1,
+		DBAutoMigrate:     true,
+	}
+}
+
+func closeDB(t *testing.T, db *gorm.DB) {
+	t.Cleanup(func() {
+		if err := CloseDB(db); err != nil {
+			t.Errorf("CloseDB() error = %v", err)
+		}
+	})
+}
+
+func TestConnectDBSQLite(t *testing.T) {
+	cfg := sqliteConfig()
+	db := ConnectDB(cfg)
+	closeDB(t, db)
+
+	migrator, err := NewMigrator(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	statuses, err := migrator.Status(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, status := range statuses {
+		if status.AppliedAt == nil || status.Missing {
+			t.Errorf("migration %d_%s not applied", status.Version, status.Name)
+		}
+	}
+
+	var roles []models.Role
+	if err := db.Find(&roles).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(roles) == 0 {
+		t.Error("roles were not seeded")
+	}
+
+	// Every in-memory database is separate.
+	other := OpenDB(cfg)
+	closeDB(t, other)
+	if other.Migrator().HasTable("users") {
+		t.Error("a second in-memory database shares the first one's tables")
+	}
+}
+
+func TestConnectDBPendingMigrations(t *testing.T) {
+	cfg := sqliteConfig()
+	cfg.DBAutoMigrate = false
+
+	// Seeding the empty schema would fail and stop the process; it is
+	// skipped until the migrations are applied.
+	db := ConnectDB(cfg)
+	closeDB(t, db)
+	if db.Migrator().HasTable("roles") {
+		t.Error("schema created with DB_AUTO_MIGRATE off")
+	}
+}
+
+func TestMigrateUpDown(t *testing.T) {
+	db := OpenDB(sqliteConfig())
+	closeDB(t, db)
+	ctx := context.Background()
+
+	migrator, err := NewMigrator(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tables := []string{"users", "roles", "samples", "sample_images", "refresh_tokens", "login_throttles"}
+
+	applied, err := migrator.Up(ctx)
+	if err != nil {
+		t.Fatalf("Up() error = %v", err)
+	}
+	if len(applied) != len(migrator.migrations) {
+		t.Fatalf("Up() applied %d migrations, want %d", len(applied), len(migrator.migrations))
+	}
+	for _, table := range tables {
+		if !db.Migrator().HasTable(table) {
+			t.Errorf("table %s missing after Up()", table)
+		}
+	}
+
+	if applied, err := migrator.Up(ctx); err != nil || len(applied) != 0 {
+		t.Errorf("second Up() = %d migrations, %v; want none", len(applied), err)
+	}
+
+	rolledBack, err := migrator.Down(ctx, len(migrator.migrations))
+	if err != nil {
+		t.Fatalf("Down() error = %v", err)
+	}
+	if len(rolledBack) != len(migrator.migrations) {
+		t.Fatalf("Down() rolled back %d migrations, want %d", len(rolledBack), len(migrator.migrations))
+	}
+	for _, table := range tables {
+		if db.Migrator().HasTable(table) {
+			t.Errorf("table %s left after Down()", table)
+		}
+	}
+
+	// The down migrations leave nothing behind that the up ones trip over.
+	if applied, err := migrator.Up(ctx); err != nil || len(applied) != len(migrator.migrations) {
+		t.Errorf("Up() after Down() = %d migrations, %v; want %d", len(applied), err, len(migrator.migrations))
+	}
+}
+
+func TestMigrateAutoMigratedDatabase(t *testing.T) {
+	db := OpenDB(sqliteConfig())
+	closeDB(t, db)
+	ctx := context.Background()
+
+	// The tables GORM AutoMigrate created before versioned migrations.
+	type User struct {
+		ID        uint   `gorm:"primaryKey"`
+		Email     string `gorm:"uniqueIndex;not null"`
+		Password  string `gorm:"not null"`
+		FirstName string `gorm:"not null"`
+		LastName  string `gorm:"not null"`
+		IsActive  bool   `gorm:"default:true"`
+		CreatedAt time.Time
+		UpdatedAt time.Time
+		DeletedAt gorm.DeletedAt `gorm:"index"`
+	}
+	type Sample struct {
+		ID            uint   `gorm:"primaryKey"`
+		Title         string `gorm:"not null"`
+		Description   string
+		ImageURL      string
+		ImagePublicID string `gorm:"column:image_public_id"`
+		UserID        uint   `gorm:"not null"`
+		User          User   `gorm:"foreignKey:UserID"`
+		CreatedAt     time.Time
+		UpdatedAt     time.Time
+		DeletedAt     gorm.DeletedAt `gorm:"index"`
+	}
+	type PasswordResetToken struct {
+		ID        uint      `gorm:"primaryKey"`
+		UserID    uint      `gorm:"index"`
+		TokenHash string    `gorm:"uniqueIndex;not null"`
+		ExpiresAt time.Time `gorm:"not null"`
+		Used      bool      `gorm:"default:false"`
+		CreatedAt time.Time
+	}
+	if err := db.AutoMigrate(&User{}, &Sample{}, &PasswordResetToken{}); err != nil {
+		t.Fatal(err)
+	}
+
+	user := User{Email: "old@example.com", Password: "hash", FirstName: "Old", LastName: "User", IsActive: true}
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatal(err)
+	}
+	for _, sample := range []Sample{
+		{Title: "With image", ImageURL: "http://cdn/a.png", ImagePublicID: "samples/a", UserID: user.ID},
+		{Title: "Without image", UserID: user.ID},
+	} {
+		if err := db.Create(&sample).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	migrator, err := NewMigrator(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := migrator.Up(ctx); err != nil {
+		t.Fatalf("Up() error = %v", err)
+	}
+
+	var migrated models.User
+	if err := db.First(&migrated, user.ID).Error; err != nil {
+		t.Fatalf("loading a user with the current model: %v", err)
+	}
+	if migrated.TokenVersion != 0 || migrated.TOTPEnabled {
+		t.Errorf("migrated user = %+v, want the new columns at their defaults", migrated)
+	}
+
+	var images []models.SampleImage
+	if err := db.Find(&images).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(images) != 1 || images[0].PublicID != "samples/a" || images[0].Position != 0 {
+		t.Fatalf("backfilled images = %+v, want the image of the first sample", images)
+	}
+
+	// Rolling back the variants and the backfill removes the copied image.
+	if _, err := migrator.Down(ctx, 2); err != nil {
+		t.Fatalf("Down() error = %v", err)
+	}
+	var count int64
+	if err := db.Table("sample_images").Count(&count).Error; err != nil {
+		t.Fatal(err)
+	}
+	if count != 0 {
+		t.Errorf("%d gallery images left after rolling back the backfill, want 0", count)
+	}
+}
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS "password_reset_tokens";
+DROP TABLE IF EXISTS "samples";
+DROP TABLE IF EXISTS "users";
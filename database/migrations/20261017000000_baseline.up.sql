@@ -0,0 +1,46 @@
+-- Baseline: the schema GORM AutoMigrate created for User, Sample and
+-- PasswordResetToken before versioned migrations. Every statement is
+-- idempotent so databases created that way adopt it unchanged; the later
+-- migrations bring them up to date.
+
+CREATE TABLE IF NOT EXISTS "users" (
+    "id" bigserial,
+    "email" text NOT NULL,
+    "password" text NOT NULL,
+    "first_name" text NOT NULL,
+    "last_name" text NOT NULL,
+    "is_active" boolean DEFAULT true,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_users_deleted_at" ON "users" ("deleted_at");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_users_email" ON "users" ("email");
+
+CREATE TABLE IF NOT EXISTS "samples" (
+    "id" bigserial,
+    "title" text NOT NULL,
+    "description" text,
+    "image_url" text,
+    "image_public_id" text,
+    "user_id" bigint NOT NULL,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_samples_user" FOREIGN KEY ("user_id") REFERENCES "users"("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_samples_deleted_at" ON "samples" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "password_reset_tokens" (
+    "id" bigserial,
+    "user_id" bigint,
+    "token_hash" text NOT NULL,
+    "expires_at" timestamptz NOT NULL,
+    "used" boolean DEFAULT false,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_password_reset_tokens_token_hash" ON "password_reset_tokens" ("token_hash");
+CREATE INDEX IF NOT EXISTS "idx_password_reset_tokens_user_id" ON "password_reset_tokens" ("user_id");
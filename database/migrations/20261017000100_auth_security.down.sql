@@ -0,0 +1,11 @@
+DROP TABLE IF EXISTS "account_unlock_tokens";
+DROP TABLE IF EXISTS "login_throttles";
+DROP TABLE IF EXISTS "mfa_recovery_codes";
+DROP TABLE IF EXISTS "email_verification_tokens";
+DROP TABLE IF EXISTS "revoked_tokens";
+DROP TABLE IF EXISTS "refresh_tokens";
+ALTER TABLE "users" DROP COLUMN IF EXISTS "totp_last_step";
+ALTER TABLE "users" DROP COLUMN IF EXISTS "totp_enabled";
+ALTER TABLE "users" DROP COLUMN IF EXISTS "totp_secret";
+ALTER TABLE "users" DROP COLUMN IF EXISTS "email_verified_at";
+ALTER TABLE "users" DROP COLUMN IF EXISTS "token_version";
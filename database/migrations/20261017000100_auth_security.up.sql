@@ -0,0 +1,82 @@
+-- Sessions (token version, refresh and revoked tokens), email verification,
+-- TOTP MFA and failed-login throttling.
+
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "token_version" bigint NOT NULL DEFAULT 0;
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "email_verified_at" timestamptz;
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "totp_secret" text;
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "totp_enabled" boolean DEFAULT false;
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "totp_last_step" bigint DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS "refresh_tokens" (
+    "id" bigserial,
+    "user_id" bigint NOT NULL,
+    "family_id" text NOT NULL,
+    "token_hash" text NOT NULL,
+    "expires_at" timestamptz NOT NULL,
+    "used_at" timestamptz,
+    "revoked_at" timestamptz,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_refresh_tokens_token_hash" ON "refresh_tokens" ("token_hash");
+CREATE INDEX IF NOT EXISTS "idx_refresh_tokens_family_id" ON "refresh_tokens" ("family_id");
+CREATE INDEX IF NOT EXISTS "idx_refresh_tokens_user_id" ON "refresh_tokens" ("user_id");
+
+CREATE TABLE IF NOT EXISTS "revoked_tokens" (
+    "id" bigserial,
+    "jti" text NOT NULL,
+    "user_id" bigint NOT NULL,
+    "expires_at" timestamptz NOT NULL,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_revoked_tokens_expires_at" ON "revoked_tokens" ("expires_at");
+CREATE INDEX IF NOT EXISTS "idx_revoked_tokens_user_id" ON "revoked_tokens" ("user_id");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_revoked_tokens_jti" ON "revoked_tokens" ("jti");
+
+CREATE TABLE IF NOT EXISTS "email_verification_tokens" (
+    "id" bigserial,
+    "user_id" bigint,
+    "token_hash" text NOT NULL,
+    "expires_at" timestamptz NOT NULL,
+    "used" boolean DEFAULT false,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_email_verification_tokens_token_hash" ON "email_verification_tokens" ("token_hash");
+CREATE INDEX IF NOT EXISTS "idx_email_verification_tokens_user_id" ON "email_verification_tokens" ("user_id");
+
+CREATE TABLE IF NOT EXISTS "mfa_recovery_codes" (
+    "id" bigserial,
+    "user_id" bigint NOT NULL,
+    "code_hash" text NOT NULL,
+    "used_at" timestamptz,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_mfa_recovery_codes_user_id" ON "mfa_recovery_codes" ("user_id");
+
+CREATE TABLE IF NOT EXISTS "login_throttles" (
+    "id" bigserial,
+    "email_hash" varchar(64) NOT NULL,
+    "failed_attempts" bigint NOT NULL DEFAULT 0,
+    "last_failed_at" timestamptz,
+    "locked_until" timestamptz,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_login_throttles_last_failed_at" ON "login_throttles" ("last_failed_at");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_login_throttles_email_hash" ON "login_throttles" ("email_hash");
+
+CREATE TABLE IF NOT EXISTS "account_unlock_tokens" (
+    "id" bigserial,
+    "user_id" bigint,
+    "token_hash" text NOT NULL,
+    "expires_at" timestamptz NOT NULL,
+    "used" boolean DEFAULT false,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_account_unlock_tokens_token_hash" ON "account_unlock_tokens" ("token_hash");
+CREATE INDEX IF NOT EXISTS "idx_account_unlock_tokens_user_id" ON "account_unlock_tokens" ("user_id");
@@ -0,0 +1,33 @@
+-- Roles and permissions. The default ones are seeded on startup once
+-- migrations are up to date (database.SeedRoles).
+
+CREATE TABLE IF NOT EXISTS "roles" (
+    "id" bigserial,
+    "name" text NOT NULL,
+    "description" text,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_roles_name" ON "roles" ("name");
+
+CREATE TABLE IF NOT EXISTS "permissions" (
+    "id" bigserial,
+    "name" text NOT NULL,
+    "description" text,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_permissions_name" ON "permissions" ("name");
+
+CREATE TABLE IF NOT EXISTS "user_roles" (
+    "user_id" bigint,
+    "role_id" bigint,
+    PRIMARY KEY ("user_id", "role_id")
+);
+
+CREATE TABLE IF NOT EXISTS "role_permissions" (
+    "role_id" bigint,
+    "permission_id" bigint,
+    PRIMARY KEY ("role_id", "permission_id")
+);
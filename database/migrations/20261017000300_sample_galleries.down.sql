@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS "pending_asset_deletions";
+DROP TABLE IF EXISTS "sample_images";
+DROP TABLE IF EXISTS "resumable_uploads";
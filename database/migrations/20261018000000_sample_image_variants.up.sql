@@ -0,0 +1,3 @@
+-- The renditions of each gallery image with their URL and dimensions, as
+-- reported by storage when the image was stored.
+ALTER TABLE "sample_images" ADD COLUMN IF NOT EXISTS "variants" jsonb NOT NULL DEFAULT '[]';
@@ -0,0 +1,27 @@
+// Package migrations holds the versioned SQL migrations applied by
+// database.Migrator. Each version is a pair of files named
+// <version>_<name>.up.sql and <version>_<name>.down.sql, and every file runs
+// in its own transaction. The files here are for Postgres; sqlite/ holds the
+// same versions written for SQLite. "migrate create <name>" writes a new pair
+// to both.
+package migrations
+
+import (
+	"embed"
+	"io/fs"
+)
+
+//go:embed *.sql
+var FS embed.FS
+
+//go:embed sqlite/*.sql
+var sqliteFS embed.FS
+
+// ForDialect returns the migrations for a GORM dialect name, "postgres" or
+// "sqlite".
+func ForDialect(name string) (fs.FS, error) {
+	if name == "sqlite" {
+		return fs.Sub(sqliteFS, "sqlite")
+	}
+	return FS, nil
+}
@@ -0,0 +1,44 @@
+-- Baseline: the schema of ../20261017000000_baseline.up.sql for SQLite.
+-- Column types follow GORM's SQLite dialect so the models map unchanged.
+
+CREATE TABLE IF NOT EXISTS "users" (
+    "id" integer,
+    "email" text NOT NULL,
+    "password" text NOT NULL,
+    "first_name" text NOT NULL,
+    "last_name" text NOT NULL,
+    "is_active" numeric DEFAULT true,
+    "created_at" datetime,
+    "updated_at" datetime,
+    "deleted_at" datetime,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_users_deleted_at" ON "users" ("deleted_at");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_users_email" ON "users" ("email");
+
+CREATE TABLE IF NOT EXISTS "samples" (
+    "id" integer,
+    "title" text NOT NULL,
+    "description" text,
+    "image_url" text,
+    "image_public_id" text,
+    "user_id" integer NOT NULL,
+    "created_at" datetime,
+    "updated_at" datetime,
+    "deleted_at" datetime,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_samples_user" FOREIGN KEY ("user_id") REFERENCES "users"("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_samples_deleted_at" ON "samples" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "password_reset_tokens" (
+    "id" integer,
+    "user_id" integer,
+    "token_hash" text NOT NULL,
+    "expires_at" datetime NOT NULL,
+    "used" numeric DEFAULT false,
+    "created_at" datetime,
+    PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_password_reset_tokens_token_hash" ON "password_reset_tokens" ("token_hash");
+CREATE INDEX IF NOT EXISTS "idx_password_reset_tokens_user_id" ON "password_reset_tokens" ("user_id");
@@ -0,0 +1,33 @@
+-- Roles and permissions. The default ones are seeded on startup once
+-- migrations are up to date (database.SeedRoles).
+
+CREATE TABLE IF NOT EXISTS "roles" (
+    "id" integer,
+    "name" text NOT NULL,
+    "description" text,
+    "created_at" datetime,
+    "updated_at" datetime,
+    PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_roles_name" ON "roles" ("name");
+
+CREATE TABLE IF NOT EXISTS "permissions" (
+    "id" integer,
+    "name" text NOT NULL,
+    "description" text,
+    "created_at" datetime,
+    PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_permissions_name" ON "permissions" ("name");
+
+CREATE TABLE IF NOT EXISTS "user_roles" (
+    "user_id" integer,
+    "role_id" integer,
+    PRIMARY KEY ("user_id", "role_id")
+);
+
+CREATE TABLE IF NOT EXISTS "role_permissions" (
+    "role_id" integer,
+    "permission_id" integer,
+    PRIMARY KEY ("role_id", "permission_id")
+);
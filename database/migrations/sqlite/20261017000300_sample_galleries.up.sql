@@ -0,0 +1,47 @@
+-- Resumable (tus) uploads, sample image galleries and the queue of storage
+-- assets whose deletion is retried.
+
+CREATE TABLE IF NOT EXISTS "resumable_uploads" (
+    "id" varchar(64),
+    "user_id" integer NOT NULL,
+    "filename" text,
+    "file_type" text,
+    "upload_length" integer NOT NULL,
+    "upload_offset" integer NOT NULL DEFAULT 0,
+    "completed_at" datetime,
+    "expires_at" datetime NOT NULL,
+    "created_at" datetime,
+    "updated_at" datetime,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_resumable_uploads_expires_at" ON "resumable_uploads" ("expires_at");
+CREATE INDEX IF NOT EXISTS "idx_resumable_uploads_user_id" ON "resumable_uploads" ("user_id");
+
+CREATE TABLE IF NOT EXISTS "sample_images" (
+    "id" integer,
+    "sample_id" integer NOT NULL,
+    "position" integer NOT NULL DEFAULT 0,
+    "url" text NOT NULL,
+    "public_id" text NOT NULL,
+    "alt_text" text,
+    "width" integer,
+    "height" integer,
+    "created_at" datetime,
+    "updated_at" datetime,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_samples_images" FOREIGN KEY ("sample_id") REFERENCES "samples"("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_sample_images_sample_id" ON "sample_images" ("sample_id");
+
+CREATE TABLE IF NOT EXISTS "pending_asset_deletions" (
+    "id" integer,
+    "public_id" varchar(512) NOT NULL,
+    "attempts" integer NOT NULL DEFAULT 0,
+    "last_error" text,
+    "next_attempt_at" datetime NOT NULL,
+    "created_at" datetime,
+    "updated_at" datetime,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_pending_asset_deletions_next_attempt_at" ON "pending_asset_deletions" ("next_attempt_at");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_pending_asset_deletions_public_id" ON "pending_asset_deletions" ("public_id");
@@ -0,0 +1,17 @@
+-- Removes the gallery images the up migration created: a sample's only image
+-- that copies the sample's own image, with no alt text or dimensions.
+DELETE FROM sample_images
+WHERE position = 0
+    AND width = 0
+    AND height = 0
+    AND alt_text = ''
+    AND EXISTS (
+        SELECT 1 FROM samples
+        WHERE samples.id = sample_images.sample_id
+            AND samples.image_public_id = sample_images.public_id
+    )
+    AND NOT EXISTS (
+        SELECT 1 FROM sample_images AS others
+        WHERE others.sample_id = sample_images.sample_id
+            AND others.id <> sample_images.id
+    );
@@ -0,0 +1,8 @@
+-- Turns the single image of samples created before galleries existed into
+-- their first gallery image.
+INSERT INTO sample_images (sample_id, position, url, public_id, alt_text, width, height, created_at, updated_at)
+SELECT samples.id, 0, samples.image_url, samples.image_public_id, '', 0, 0, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP
+FROM samples
+WHERE samples.image_public_id <> ''
+    AND samples.deleted_at IS NULL
+    AND NOT EXISTS (SELECT 1 FROM sample_images WHERE sample_images.sample_id = samples.id);
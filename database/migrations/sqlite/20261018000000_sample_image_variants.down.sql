@@ -0,0 +1 @@
+ALTER TABLE "sample_images" DROP COLUMN "variants";
@@ -0,0 +1,209 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"sync/atomic"
+	"time"
+
+	"go-fiber-boilerplate/config"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+const (
+	// replicaCheckTimeout bounds one health check of a replica.
+	replicaCheckTimeout = 5 * time.Second
+	// replicaLagSlack is how many bytes of WAL a replica may trail the
+	// primary by and still count as caught up, covering the records in
+	// flight between them.
+	replicaLagSlack = 1 << 20
+)
+
+// Replicas hands out connections for reads that tolerate replication lag.
+// Reader picks the healthy read replicas from DB_READ_REPLICA_URLS in turn
+// and falls back to the primary when there are none, so callers never have
+// to care whether replicas are configured or up.
+//
+// A replica is healthy when it answers within replicaCheckTimeout, is still
+// in recovery and lags the primary by at most DB_REPLICA_MAX_LAG. Start
+// re-checks them every DB_REPLICA_CHECK_INTERVAL.
+type Replicas struct {
+	primary  *gorm.DB
+	replicas []*replica
+	maxLag   time.Duration
+	next     atomic.Uint64
+}
+
+type replica struct {
+	name    string
+	db      *gorm.DB
+	healthy atomic.Bool
+	checked bool
+}
+
+// OpenReplicas connects to the configured read replicas and checks them
+// once. Replicas that are down are not fatal: they join once they recover.
+func OpenReplicas(cfg *config.Config, primary *gorm.DB) *Replicas {
+	r := &Replicas{primary: primary, maxLag: cfg.DBReplicaMaxLag}
+
+	for _, replicaURL := range cfg.DBReadReplicaURLs {
+		name := replicaURL
+		if u, err := url.Parse(replicaURL); err == nil {
+			name = u.Host
+		}
+
+		dsn, err := urlDataSourceName(cfg, replicaURL)
+		if err != nil {
+			log.Fatalf("Invalid read replica %s: %v", name, err)
+		}
+		db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
+			Logger:               logger.Default.LogMode(logger.Silent),
+			DisableAutomaticPing: true,
+		})
+		if err == nil {
+			err = configurePool(db, cfg)
+		}
+		if err != nil {
+			log.Fatalf("Failed to open read replica %s: %v", name, err)
+		}
+
+		r.replicas = append(r.replicas, &replica{name: name, db: db})
+	}
+
+	r.check(context.Background())
+	if len(r.replicas) > 0 {
+		log.Printf("Read replicas configured: %d", len(r.replicas))
+	}
+	return r
+}
+
+// Reader returns a healthy replica, or the primary when there is none.
+func (r *Replicas) Reader() *gorm.DB {
+	n := uint64(len(r.replicas))
+	start := r.next.Add(1)
+	for i := uint64(0); i < n; i++ {
+		if rep := r.replicas[(start+i)%n]; rep.healthy.Load() {
+			return rep.db
+		}
+	}
+	return r.primary
+}
+
+// Start re-checks the replicas every interval until ctx is done.
+func (r *Replicas) Start(ctx context.Context, interval time.Duration) {
+	if len(r.replicas) == 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.check(ctx)
+		}
+	}
+}
+
+// Close closes the replica connection pools; the primary is left open.
+func (r *Replicas) Close() error {
+	var firstErr error
+	for _, rep := range r.replicas {
+		sqlDB, err := rep.db.DB()
+		if err == nil {
+			err = sqlDB.Close()
+		}
+		if err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("read replica %s: %w", rep.name, err)
+		}
+	}
+	return firstErr
+}
+
+func (r *Replicas) check(ctx context.Context) {
+	for _, rep := range r.replicas {
+		err := r.probe(ctx, rep)
+		healthy := err == nil
+		if rep.healthy.Swap(healthy) == healthy && rep.checked {
+			continue
+		}
+		rep.checked = true
+		if healthy {
+			log.Printf("Read replica %s is healthy", rep.name)
+		} else {
+			log.Printf("warning: read replica %s is unhealthy, reads fall back: %v", rep.name, err)
+		}
+	}
+}
+
+// replicaStatus is what probe reads from a replica.
+type replicaStatus struct {
+	InRecovery bool
+	ReplayLSN  *string
+	// Pending is how many bytes of received WAL are not replayed yet; nil
+	// when the replica has no WAL receiver.
+	Pending *float64
+	// ReplayAge is how many seconds ago the last replayed transaction was
+	// committed on the primary.
+	ReplayAge *float64
+}
+
+// probe checks that the replica is still a standby and how far it trails
+// the primary's current WAL position; see lagError.
+func (r *Replicas) probe(ctx context.Context, rep *replica) error {
+	ctx, cancel := context.WithTimeout(ctx, replicaCheckTimeout)
+	defer cancel()
+
+	var status replicaStatus
+	if err := rep.db.WithContext(ctx).Raw(`SELECT
+		pg_is_in_recovery() AS in_recovery,
+		pg_last_wal_replay_lsn()::text AS replay_lsn,
+		pg_wal_lsn_diff(pg_last_wal_receive_lsn(), pg_last_wal_replay_lsn()) AS pending,
+		EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()) AS replay_age`,
+	).Scan(&status).Error; err != nil {
+		return err
+	}
+	if !status.InRecovery || status.ReplayLSN == nil {
+		return fmt.Errorf("not in recovery, it was promoted or is not a replica")
+	}
+
+	var behind float64
+	if err := r.primary.WithContext(ctx).Raw(
+		"SELECT pg_wal_lsn_diff(pg_current_wal_lsn(), ?::pg_lsn)", *status.ReplayLSN,
+	).Scan(&behind).Error; err != nil {
+		return fmt.Errorf("failed to read the primary WAL position: %w", err)
+	}
+	return lagError(status, behind, r.maxLag)
+}
+
+// lagError decides whether a replica that has replayed WAL up to behind
+// bytes short of the primary is too far behind. Within replicaLagSlack it is
+// caught up, however long the primary has been idle. Further behind, a
+// replica with received WAL still to replay lags by the age of the last
+// transaction it replayed, which is only meaningful while WAL is pending;
+// one that has replayed everything it received is not getting WAL from the
+// primary, e.g. because its WAL receiver is disconnected.
+func lagError(status replicaStatus, behind float64, maxLag time.Duration) error {
+	if behind <= replicaLagSlack {
+		return nil
+	}
+
+	if status.Pending == nil || *status.Pending <= 0 {
+		return fmt.Errorf("%.0f bytes of WAL behind the primary and not receiving them", behind)
+	}
+	if status.ReplayAge == nil {
+		return fmt.Errorf("behind the primary and has replayed no transaction since it started")
+	}
+	if lag := time.Duration(*status.ReplayAge * float64(time.Second)); lag > maxLag {
+		return fmt.Errorf("replication lag %s exceeds %s", lag.Round(time.Second), maxLag)
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package database
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLagError(t *testing.T) {
+	ptr := func(v float64) *float64 { return &v }
+	maxLag := 30 * time.Second
+
+	tests := []struct {
+		name    string
+		status  replicaStatus
+		behind  float64
+		healthy bool
+	}{
+		{"caught up after a long idle period", replicaStatus{Pending: ptr(0), ReplayAge: ptr(3600)}, 0, true},
+		{"small write not replayed yet", replicaStatus{Pending: ptr(512), ReplayAge: ptr(3600)}, 512, true},
+		{"replaying within the lag", replicaStatus{Pending: ptr(4 << 20), ReplayAge: ptr(5)}, 8 << 20, true},
+		{"replaying beyond the lag", replicaStatus{Pending: ptr(4 << 20), ReplayAge: ptr(60)}, 8 << 20, false},
+		{"receiver disconnected", replicaStatus{Pending: ptr(0), ReplayAge: ptr(1)}, 8 << 20, false},
+		{"no WAL receiver", replicaStatus{ReplayAge: ptr(1)}, 8 << 20, false},
+		{"nothing replayed since start", replicaStatus{Pending: ptr(4 << 20)}, 8 << 20, false},
+	}
+	for _, tt := range tests {
+		err := lagError(tt.status, tt.behind, maxLag)
+		if (err == nil) != tt.healthy {
+			t.Errorf("%s: lagError() = %v, want healthy %v", tt.name, err, tt.healthy)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package database
+
+import (
+	"log"
+
+	"go-fiber-boilerplate/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// SeedRoles makes sure the default permissions and roles exist, gives every
+// user without a role the default user role and, when adminEmail is set,
+// grants that account the admin role. It is safe to run on every startup.
+func SeedRoles(db *gorm.DB, adminEmail string) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		permissions := make(map[string]models.Permission, len(models.DefaultPermissions))
+		for name, description := range models.DefaultPermissions {
+			permission := models.Permission{}
+			if err := tx.
+				Where(models.Permission{Name: name}).
+				Attrs(models.Permission{Description: description}).
+				FirstOrCreate(&permission).Error; err != nil {
+				return err
+			}
+			permissions[name] = permission
+		}
+
+		roles := make(map[string]models.Role, len(models.DefaultRoles))
+		for name, granted := range models.DefaultRoles {
+			role := models.Role{}
+			if err := tx.Where(models.Role{Name: name}).FirstOrCreate(&role).Error; err != nil {
+				return err
+			}
+
+			if len(granted) > 0 {
+				rolePermissions := make([]models.Permission, 0, len(granted))
+				for _, permissionName := range granted {
+					rolePermissions = append(rolePermissions, permissions[permissionName])
+				}
+				if err := tx.Model(&role).Association("Permissions").Append(rolePermissions); err != nil {
+					return err
+				}
+			}
+			roles[name] = role
+		}
+
+		if err := tx.Exec(`INSERT INTO user_roles (user_id, role_id)
+			SELECT users.id, ? FROM users
+			WHERE NOT EXISTS (SELECT 1 FROM user_roles WHERE user_roles.user_id = users.id)`,
+			roles[models.RoleUser].ID).Error; err != nil {
+			return err
+		}
+
+		if adminEmail == "" {
+			return nil
+		}
+
+		var admin models.User
+		if err := tx.Where("email = ?", adminEmail).First(&admin).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				log.Printf("bootstrap admin %s not found, skipping admin role assignment", adminEmail)
+				return nil
+			}
+			return err
+		}
+
+		adminRole := roles[models.RoleAdmin]
+		return tx.Model(&admin).Association("Roles").Append(&adminRole)
+	})
+}
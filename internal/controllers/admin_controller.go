@@ -0,0 +1,159 @@
+package controllers
+
+import (
+	"go-fiber-boilerplate/internal/models"
+	"go-fiber-boilerplate/internal/services"
+	"go-fiber-boilerplate/pkg/apperror"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type AdminController struct {
+	adminService *services.AdminService
+}
+
+func NewAdminController(adminService *services.AdminService) *AdminController {
+	return &AdminController{
+		adminService: adminService,
+	}
+}
+
+func (ctrl *AdminController) ListUsers(c *fiber.Ctx) error {
+	users, meta, err := ctrl.adminService.ListUsers(paginationParams(c))
+	if err != nil {
+		return err
+	}
+
+	responses := make([]models.AdminUserResponse, 0, len(users))
+	for _, user := range users {
+		responses = append(responses, user.ToAdminResponse())
+	}
+
+	return c.JSON(fiber.Map{
+		"data": responses,
+		"meta": meta,
+	})
+}
+
+func (ctrl *AdminController) GetUser(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return ErrInvalidUserID
+	}
+
+	user, err := ctrl.adminService.GetUser(id)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(fiber.Map{"data": user.ToAdminResponse()})
+}
+
+func (ctrl *AdminController) GetUserSamples(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return ErrInvalidUserID
+	}
+
+	samples, meta, err := ctrl.adminService.GetUserSamples(id, paginationParams(c))
+	if err != nil {
+		return err
+	}
+
+	responses := make([]models.SamplePublicResponse, 0, len(samples))
+	for _, sample := range samples {
+		responses = append(responses, sample.ToPublicResponse())
+	}
+
+	return c.JSON(fiber.Map{
+		"data": responses,
+		"meta": meta,
+	})
+}
+
+func (ctrl *AdminController) UpdateUserStatus(c *fiber.Ctx) error {
+	actorID := c.Locals("userID").(uint)
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return ErrInvalidUserID
+	}
+
+	var req models.UpdateUserStatusRequest
+	if err := parseBody(c, &req); err != nil {
+		return err
+	}
+	if req.IsActive == nil {
+		return apperror.ErrBadRequest.WithMessage("is_active is required")
+	}
+
+	user, err := ctrl.adminService.SetActive(actorID, id, *req.IsActive)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "User status updated successfully",
+		"data":    user.ToAdminResponse(),
+	})
+}
+
+func (ctrl *AdminController) ForcePasswordReset(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return ErrInvalidUserID
+	}
+
+	if err := ctrl.adminService.ForcePasswordReset(id); err != nil {
+		return err
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Sessions revoked and password reset email sent",
+	})
+}
+
+func (ctrl *AdminController) UnlockUser(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return ErrInvalidUserID
+	}
+
+	if err := ctrl.adminService.UnlockUser(id); err != nil {
+		return err
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Account unlocked",
+	})
+}
+
+func (ctrl *AdminController) DeleteUser(c *fiber.Ctx) error {
+	actorID := c.Locals("userID").(uint)
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return ErrInvalidUserID
+	}
+
+	if err := ctrl.adminService.DeleteUser(actorID, id); err != nil {
+		return err
+	}
+
+	return c.JSON(fiber.Map{"message": "User deleted successfully"})
+}
+
+func (ctrl *AdminController) RestoreUser(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return ErrInvalidUserID
+	}
+
+	user, err := ctrl.adminService.RestoreUser(id)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "User restored successfully",
+		"data":    user.ToAdminResponse(),
+	})
+}
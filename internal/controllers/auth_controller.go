@@ -1,7 +1,11 @@
 package controllers
 
 import (
-	"go-fiber-boilerplate/config"
+	"errors"
+	"math"
+	"strconv"
+	"time"
+
 	"go-fiber-boilerplate/internal/models"
 	"go-fiber-boilerplate/internal/services"
 
@@ -12,35 +16,21 @@ type AuthController struct {
 	authService *services.AuthService
 }
 
-func NewAuthController(cfg *config.Config) *AuthController {
+func NewAuthController(authService *services.AuthService) *AuthController {
 	return &AuthController{
-		authService: services.NewAuthService(cfg),
+		authService: authService,
 	}
 }
 
 func (ctrl *AuthController) Register(c *fiber.Ctx) error {
 	var req models.CreateUserRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+	if err := parseBody(c, &req); err != nil {
+		return err
 	}
 
 	response, err := ctrl.authService.Register(req)
 	if err != nil {
-		switch err.Error() {
-		case "user with this email already exists",
-			"all fields are required",
-			"invalid email format",
-			"password must include upper, lower, number, special and be at least 8 characters":
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"error": err.Error(),
-			})
-		default:
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error": "Unable to process registration",
-			})
-		}
+		return err
 	}
 
 	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
@@ -51,24 +41,20 @@ func (ctrl *AuthController) Register(c *fiber.Ctx) error {
 
 func (ctrl *AuthController) Login(c *fiber.Ctx) error {
 	var req models.LoginRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+	if err := parseBody(c, &req); err != nil {
+		return err
 	}
 
 	response, err := ctrl.authService.Login(req)
 	if err != nil {
-		switch err.Error() {
-		case "email and password are required", "invalid email format":
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"error": err.Error(),
-			})
-		default:
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error": "Invalid credentials",
-			})
-		}
+		return throttled(c, err)
+	}
+
+	if response.MFARequired {
+		return c.JSON(fiber.Map{
+			"message": "MFA verification required",
+			"data":    response,
+		})
 	}
 
 	return c.JSON(fiber.Map{
@@ -77,24 +63,126 @@ func (ctrl *AuthController) Login(c *fiber.Ctx) error {
 	})
 }
 
-func (ctrl *AuthController) ForgotPassword(c *fiber.Ctx) error {
-	var req models.ForgotPasswordRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+func (ctrl *AuthController) VerifyMFA(c *fiber.Ctx) error {
+	var req models.MFAVerifyRequest
+	if err := parseBody(c, &req); err != nil {
+		return err
+	}
+
+	response, err := ctrl.authService.VerifyMFA(req.MFAToken, req.Code)
+	if err != nil {
+		return throttled(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Login successful",
+		"data":    response,
+	})
+}
+
+func (ctrl *AuthController) Refresh(c *fiber.Ctx) error {
+	var req models.RefreshTokenRequest
+	if err := parseBody(c, &req); err != nil {
+		return err
 	}
 
-	err := ctrl.authService.ForgotPassword(req.Email)
+	response, err := ctrl.authService.Refresh(req.RefreshToken)
 	if err != nil {
-		if err.Error() == "email is required" || err.Error() == "invalid email format" {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"error": err.Error(),
-			})
+		return err
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Token refreshed",
+		"data":    response,
+	})
+}
+
+func (ctrl *AuthController) Logout(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	jti := c.Locals("jti").(string)
+	expiresAt := c.Locals("tokenExpiresAt").(time.Time)
+
+	var req models.LogoutRequest
+	if len(c.Body()) > 0 {
+		if err := parseBody(c, &req); err != nil {
+			return err
 		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Unable to process request",
-		})
+	}
+
+	if err := ctrl.authService.Logout(userID, jti, expiresAt, req.RefreshToken); err != nil {
+		return err
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Logged out successfully",
+	})
+}
+
+func (ctrl *AuthController) LogoutAll(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	if err := ctrl.authService.LogoutAll(userID); err != nil {
+		return err
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Logged out from all sessions",
+	})
+}
+
+func (ctrl *AuthController) VerifyEmail(c *fiber.Ctx) error {
+	var req models.VerifyEmailRequest
+	if err := parseBody(c, &req); err != nil {
+		return err
+	}
+
+	if err := ctrl.authService.VerifyEmail(req.Token); err != nil {
+		return err
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Email verified successfully",
+	})
+}
+
+func (ctrl *AuthController) UnlockAccount(c *fiber.Ctx) error {
+	var req models.UnlockAccountRequest
+	if err := parseBody(c, &req); err != nil {
+		return err
+	}
+
+	if err := ctrl.authService.UnlockAccount(req.Token); err != nil {
+		return err
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Account unlocked successfully",
+	})
+}
+
+func (ctrl *AuthController) ResendVerification(c *fiber.Ctx) error {
+	var req models.ResendVerificationRequest
+	if err := parseBody(c, &req); err != nil {
+		return err
+	}
+
+	if err := ctrl.authService.ResendVerification(req.Email); err != nil {
+		return err
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "If the email exists and is unverified, a verification link has been sent",
+	})
+}
+
+func (ctrl *AuthController) ForgotPassword(c *fiber.Ctx) error {
+	var req models.ForgotPasswordRequest
+	if err := parseBody(c, &req); err != nil {
+		return err
+	}
+
+	if err := ctrl.authService.ForgotPassword(req.Email); err != nil {
+		return err
 	}
 
 	return c.JSON(fiber.Map{
@@ -104,35 +192,24 @@ func (ctrl *AuthController) ForgotPassword(c *fiber.Ctx) error {
 
 func (ctrl *AuthController) ResetPassword(c *fiber.Ctx) error {
 	var req models.ResetPasswordRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+	if err := parseBody(c, &req); err != nil {
+		return err
 	}
 
-	err := ctrl.authService.ResetPassword(req.Token, req.NewPassword)
-	if err != nil {
-		switch err.Error() {
-		case "token and new password are required", "password must include upper, lower, number, special and be at least 8 characters":
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"error": err.Error(),
-			})
-		case "invalid or expired reset token":
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"error": err.Error(),
-			})
-		case "user not found":
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"error": err.Error(),
-			})
-		default:
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error": "Failed to reset password",
-			})
-		}
+	if err := ctrl.authService.ResetPassword(req.Token, req.NewPassword); err != nil {
+		return err
 	}
 
 	return c.JSON(fiber.Map{
 		"message": "Password has been reset successfully",
 	})
 }
+
+// throttled sets Retry-After when err is a login throttle and returns err.
+func throttled(c *fiber.Ctx, err error) error {
+	var throttledErr *services.ThrottledError
+	if errors.As(err, &throttledErr) {
+		c.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(math.Ceil(throttledErr.RetryAfter.Seconds()))))
+	}
+	return err
+}
@@ -0,0 +1,17 @@
+package controllers
+
+import (
+	"go-fiber-boilerplate/pkg/apperror"
+	"go-fiber-boilerplate/pkg/validation"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// parseBody decodes a JSON, urlencoded or multipart body into out and checks
+// it against its `validate` tags.
+func parseBody(c *fiber.Ctx, out interface{}) error {
+	if err := c.BodyParser(out); err != nil {
+		return apperror.ErrInvalidBody
+	}
+	return validation.Struct(out)
+}
@@ -0,0 +1,18 @@
+package controllers
+
+import (
+	"net/http"
+
+	"go-fiber-boilerplate/pkg/apperror"
+)
+
+var (
+	ErrInvalidUserID          = apperror.ErrBadRequest.WithMessage("Invalid user ID")
+	ErrInvalidSampleID        = apperror.ErrBadRequest.WithMessage("Invalid sample ID")
+	ErrInvalidImageID         = apperror.ErrBadRequest.WithMessage("Invalid image ID")
+	ErrInvalidUploadLength    = apperror.ErrBadRequest.WithMessage("Invalid Upload-Length header")
+	ErrInvalidUploadOffset    = apperror.ErrBadRequest.WithMessage("Invalid Upload-Offset header")
+	ErrInvalidUploadMetadata  = apperror.ErrBadRequest.WithMessage("Invalid Upload-Metadata header")
+	ErrDeferLengthUnsupported = apperror.ErrBadRequest.WithMessage("Upload-Defer-Length is not supported")
+	ErrInvalidChunkType       = apperror.New("unsupported_media_type", http.StatusUnsupportedMediaType, "Content-Type must be application/offset+octet-stream")
+)
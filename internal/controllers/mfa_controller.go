@@ -0,0 +1,68 @@
+package controllers
+
+import (
+	"go-fiber-boilerplate/internal/models"
+	"go-fiber-boilerplate/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type MFAController struct {
+	mfaService *services.MFAService
+}
+
+func NewMFAController(mfaService *services.MFAService) *MFAController {
+	return &MFAController{
+		mfaService: mfaService,
+	}
+}
+
+func (ctrl *MFAController) Enroll(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	response, err := ctrl.mfaService.Enroll(userID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Scan the QR code and confirm with a code from your authenticator app",
+		"data":    response,
+	})
+}
+
+func (ctrl *MFAController) Confirm(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	var req models.MFAConfirmRequest
+	if err := parseBody(c, &req); err != nil {
+		return err
+	}
+
+	response, err := ctrl.mfaService.Confirm(userID, req.Code)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "MFA enabled. Store these recovery codes somewhere safe, they will not be shown again",
+		"data":    response,
+	})
+}
+
+func (ctrl *MFAController) Disable(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	var req models.MFADisableRequest
+	if err := parseBody(c, &req); err != nil {
+		return err
+	}
+
+	if err := ctrl.mfaService.Disable(userID, req.Password, req.Code); err != nil {
+		return throttled(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "MFA disabled",
+	})
+}
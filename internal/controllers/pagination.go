@@ -0,0 +1,15 @@
+package controllers
+
+import (
+	"go-fiber-boilerplate/pkg/pagination"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func paginationParams(c *fiber.Ctx) pagination.Params {
+	queryParams := make(map[string]string)
+	c.Request().URI().QueryArgs().VisitAll(func(key, value []byte) {
+		queryParams[string(key)] = string(value)
+	})
+	return pagination.NewParams(queryParams)
+}
@@ -0,0 +1,31 @@
+package controllers
+
+import (
+	"go-fiber-boilerplate/config"
+	"go-fiber-boilerplate/internal/ratelimit"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type RateLimitController struct {
+	cfg    *config.Config
+	limits ratelimit.Store
+}
+
+func NewRateLimitController(cfg *config.Config, limits ratelimit.Store) *RateLimitController {
+	return &RateLimitController{
+		cfg:    cfg,
+		limits: limits,
+	}
+}
+
+// Stats reports what the rate limit store tracks. The Redis store expires
+// its own keys and reports no stats.
+func (h *RateLimitController) Stats(c *fiber.Ctx) error {
+	data := fiber.Map{"store": h.cfg.RateLimitStore}
+	if reporter, ok := h.limits.(ratelimit.StatsReporter); ok {
+		data["stats"] = reporter.Stats()
+	}
+
+	return c.JSON(fiber.Map{"data": data})
+}
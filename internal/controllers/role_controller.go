@@ -0,0 +1,54 @@
+package controllers
+
+import (
+	"go-fiber-boilerplate/internal/models"
+	"go-fiber-boilerplate/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type RoleController struct {
+	rbacService *services.RBACService
+}
+
+func NewRoleController(rbacService *services.RBACService) *RoleController {
+	return &RoleController{
+		rbacService: rbacService,
+	}
+}
+
+func (ctrl *RoleController) GetRoles(c *fiber.Ctx) error {
+	roles, err := ctrl.rbacService.ListRoles()
+	if err != nil {
+		return err
+	}
+
+	responses := make([]models.RoleResponse, 0, len(roles))
+	for _, role := range roles {
+		responses = append(responses, role.ToResponse())
+	}
+
+	return c.JSON(fiber.Map{"data": responses})
+}
+
+func (ctrl *RoleController) SetUserRoles(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return ErrInvalidUserID
+	}
+
+	var req models.SetUserRolesRequest
+	if err := parseBody(c, &req); err != nil {
+		return err
+	}
+
+	user, err := ctrl.rbacService.SetUserRoles(uint(id), req.Roles)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Roles updated successfully",
+		"data":    user.ToResponse(),
+	})
+}
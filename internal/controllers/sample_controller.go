@@ -1,41 +1,30 @@
 package controllers
 
 import (
-	"errors"
 	"strconv"
 
-	"go-fiber-boilerplate/config"
 	"go-fiber-boilerplate/internal/models"
 	"go-fiber-boilerplate/internal/services"
-	"go-fiber-boilerplate/pkg/pagination"
 
 	"github.com/gofiber/fiber/v2"
-	"gorm.io/gorm"
 )
 
 type SampleController struct {
 	sampleService *services.SampleService
 }
 
-func NewSampleController(cfg *config.Config) *SampleController {
+func NewSampleController(sampleService *services.SampleService) *SampleController {
 	return &SampleController{
-		sampleService: services.NewSampleService(cfg),
+		sampleService: sampleService,
 	}
 }
 
 func (h *SampleController) GetSamples(c *fiber.Ctx) error {
-	queryParams := make(map[string]string)
-	c.Request().URI().QueryArgs().VisitAll(func(key, value []byte) {
-		queryParams[string(key)] = string(value)
-	})
-
-	params := pagination.NewParams(queryParams)
+	params := paginationParams(c)
 
 	samples, meta, err := h.sampleService.GetSamples(params)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to fetch samples",
-		})
+		return err
 	}
 
 	var responses []models.SamplePublicResponse
@@ -52,15 +41,12 @@ func (h *SampleController) GetSamples(c *fiber.Ctx) error {
 func (h *SampleController) GetSampleById(c *fiber.Ctx) error {
 	id, err := strconv.Atoi(c.Params("id"))
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid sample ID"})
+		return ErrInvalidSampleID
 	}
 
 	sample, err := h.sampleService.GetSampleById(id)
 	if err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Sample not found"})
-		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Database error"})
+		return err
 	}
 
 	return c.JSON(fiber.Map{"data": sample.ToResponse()})
@@ -70,10 +56,8 @@ func (c *SampleController) CreateSample(ctx *fiber.Ctx) error {
 	userID := ctx.Locals("userID").(uint)
 
 	var req models.CreateSampleRequest
-	if err := ctx.BodyParser(&req); err != nil {
-		return ctx.Status(400).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+	if err := parseBody(ctx, &req); err != nil {
+		return err
 	}
 
 	// Get image file from form
@@ -81,9 +65,7 @@ func (c *SampleController) CreateSample(ctx *fiber.Ctx) error {
 
 	sample, err := c.sampleService.CreateSample(userID, req, imageFile)
 	if err != nil {
-		return ctx.Status(500).JSON(fiber.Map{
-			"error": "Failed to create sample",
-		})
+		return err
 	}
 
 	return ctx.Status(201).JSON(fiber.Map{
@@ -96,16 +78,12 @@ func (c *SampleController) UpdateSample(ctx *fiber.Ctx) error {
 	userID := ctx.Locals("userID").(uint)
 	id, err := ctx.ParamsInt("id")
 	if err != nil {
-		return ctx.Status(400).JSON(fiber.Map{
-			"error": "Invalid ID parameter",
-		})
+		return ErrInvalidSampleID
 	}
 
 	var req models.UpdateSampleRequest
-	if err := ctx.BodyParser(&req); err != nil {
-		return ctx.Status(400).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+	if err := parseBody(ctx, &req); err != nil {
+		return err
 	}
 
 	// Get image file from form
@@ -113,19 +91,7 @@ func (c *SampleController) UpdateSample(ctx *fiber.Ctx) error {
 
 	sample, err := c.sampleService.UpdateSample(userID, id, req, imageFile)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return ctx.Status(404).JSON(fiber.Map{
-				"error": "Sample not found",
-			})
-		}
-		if err.Error() == "forbidden" {
-			return ctx.Status(403).JSON(fiber.Map{
-				"error": "You don't have permission to update this sample",
-			})
-		}
-		return ctx.Status(500).JSON(fiber.Map{
-			"error": "Failed to update sample",
-		})
+		return err
 	}
 
 	return ctx.JSON(fiber.Map{
@@ -134,21 +100,144 @@ func (c *SampleController) UpdateSample(ctx *fiber.Ctx) error {
 	})
 }
 
+func (h *SampleController) AttachImage(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return ErrInvalidSampleID
+	}
+
+	var req models.AttachUploadRequest
+	if err := parseBody(c, &req); err != nil {
+		return err
+	}
+
+	sample, err := h.sampleService.AttachUpload(userID, id, req.UploadID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Sample image updated successfully",
+		"data":    sample.ToResponse(),
+	})
+}
+
+func (h *SampleController) AddImage(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return ErrInvalidSampleID
+	}
+
+	var req models.SampleImageRequest
+	if err := parseBody(c, &req); err != nil {
+		return err
+	}
+	imageFile, _ := c.FormFile("image")
+
+	image, err := h.sampleService.AddImage(userID, id, services.ImageInput{
+		File:     imageFile,
+		UploadID: req.UploadID,
+		AltText:  req.AltText,
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.Status(201).JSON(fiber.Map{
+		"message": "Sample image added successfully",
+		"data":    image.ToResponse(),
+	})
+}
+
+func (h *SampleController) ReplaceImage(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return ErrInvalidSampleID
+	}
+	imageID, err := c.ParamsInt("imageId")
+	if err != nil {
+		return ErrInvalidImageID
+	}
+
+	var req models.SampleImageRequest
+	if err := parseBody(c, &req); err != nil {
+		return err
+	}
+	imageFile, _ := c.FormFile("image")
+
+	image, err := h.sampleService.ReplaceImage(userID, id, imageID, services.ImageInput{
+		File:     imageFile,
+		UploadID: req.UploadID,
+		AltText:  req.AltText,
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Sample image updated successfully",
+		"data":    image.ToResponse(),
+	})
+}
+
+func (h *SampleController) ReorderImages(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return ErrInvalidSampleID
+	}
+
+	var req models.ReorderSampleImagesRequest
+	if err := parseBody(c, &req); err != nil {
+		return err
+	}
+
+	images, err := h.sampleService.ReorderImages(userID, id, req.ImageIDs)
+	if err != nil {
+		return err
+	}
+
+	responses := make([]models.SampleImageResponse, 0, len(images))
+	for _, image := range images {
+		responses = append(responses, image.ToResponse())
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Sample images reordered successfully",
+		"data":    responses,
+	})
+}
+
+func (h *SampleController) DeleteImage(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return ErrInvalidSampleID
+	}
+	imageID, err := c.ParamsInt("imageId")
+	if err != nil {
+		return ErrInvalidImageID
+	}
+
+	if err := h.sampleService.DeleteImage(userID, id, imageID); err != nil {
+		return err
+	}
+
+	return c.JSON(fiber.Map{"message": "Sample image deleted successfully"})
+}
+
 func (h *SampleController) DeleteSample(c *fiber.Ctx) error {
 	userID := c.Locals("userID").(uint)
 	id, err := strconv.Atoi(c.Params("id"))
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid sample ID"})
+		return ErrInvalidSampleID
 	}
 
 	if err := h.sampleService.DeleteSample(userID, id); err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Sample not found"})
-		}
-		if err.Error() == "forbidden" {
-			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "You can only delete your own samples"})
-		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to delete sample"})
+		return err
 	}
 
 	return c.JSON(fiber.Map{"message": "Sample deleted successfully"})
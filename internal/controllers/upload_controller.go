@@ -0,0 +1,146 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"go-fiber-boilerplate/config"
+	"go-fiber-boilerplate/internal/middlewares"
+	"go-fiber-boilerplate/internal/models"
+	"go-fiber-boilerplate/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const tusExtensions = "creation,termination,expiration"
+
+// UploadController serves the tus 1.0.0 core protocol with the creation,
+// termination and expiration extensions.
+type UploadController struct {
+	cfg           *config.Config
+	uploadService *services.UploadService
+}
+
+func NewUploadController(cfg *config.Config, uploadService *services.UploadService) *UploadController {
+	return &UploadController{
+		cfg:           cfg,
+		uploadService: uploadService,
+	}
+}
+
+func (h *UploadController) Options(c *fiber.Ctx) error {
+	c.Set("Tus-Version", middlewares.TusVersion)
+	c.Set("Tus-Extension", tusExtensions)
+	c.Set("Tus-Max-Size", strconv.FormatInt(h.cfg.TusMaxSize, 10))
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func (h *UploadController) Create(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	if c.Get("Upload-Defer-Length") != "" {
+		return ErrDeferLengthUnsupported
+	}
+	length, err := strconv.ParseInt(c.Get("Upload-Length"), 10, 64)
+	if err != nil || length < 0 {
+		return ErrInvalidUploadLength
+	}
+	metadata, err := parseUploadMetadata(c.Get("Upload-Metadata"))
+	if err != nil {
+		return err
+	}
+
+	upload, err := h.uploadService.Create(userID, length, metadata["filename"], metadata["filetype"])
+	if err != nil {
+		return err
+	}
+
+	c.Set(fiber.HeaderLocation, c.BaseURL()+strings.TrimSuffix(c.Path(), "/")+"/"+upload.ID)
+	setUploadHeaders(c, upload)
+	return c.SendStatus(fiber.StatusCreated)
+}
+
+func (h *UploadController) Head(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	upload, err := h.uploadService.Get(userID, c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	c.Set("Upload-Length", strconv.FormatInt(upload.Length, 10))
+	setUploadHeaders(c, upload)
+	return c.SendStatus(fiber.StatusOK)
+}
+
+func (h *UploadController) Patch(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	if c.Get(fiber.HeaderContentType) != "application/offset+octet-stream" {
+		return ErrInvalidChunkType
+	}
+	offset, err := strconv.ParseInt(c.Get("Upload-Offset"), 10, 64)
+	if err != nil || offset < 0 {
+		return ErrInvalidUploadOffset
+	}
+
+	// The chunk is read from the request stream, so it is not bound by the
+	// app's BodyLimit; Append stops at the declared upload length.
+	var chunk io.Reader = bytes.NewReader(c.Body())
+	if c.Request().IsBodyStream() {
+		chunk = c.Context().RequestBodyStream()
+	}
+
+	upload, err := h.uploadService.Append(userID, c.Params("id"), offset, chunk)
+	if err != nil {
+		return err
+	}
+
+	setUploadHeaders(c, upload)
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func (h *UploadController) Delete(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	if err := h.uploadService.Delete(userID, c.Params("id")); err != nil {
+		return err
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func setUploadHeaders(c *fiber.Ctx, upload *models.ResumableUpload) {
+	c.Set("Upload-Offset", strconv.FormatInt(upload.Offset, 10))
+	c.Set("Upload-Expires", upload.ExpiresAt.UTC().Format(http.TimeFormat))
+}
+
+// parseUploadMetadata decodes an Upload-Metadata header: comma separated
+// "key base64value" pairs, where the value may be omitted.
+func parseUploadMetadata(header string) (map[string]string, error) {
+	metadata := make(map[string]string)
+	if strings.TrimSpace(header) == "" {
+		return metadata, nil
+	}
+
+	for _, pair := range strings.Split(header, ",") {
+		parts := strings.Fields(pair)
+		switch len(parts) {
+		case 1:
+			metadata[parts[0]] = ""
+		case 2:
+			value, err := base64.StdEncoding.DecodeString(parts[1])
+			if err != nil {
+				return nil, ErrInvalidUploadMetadata
+			}
+			metadata[parts[0]] = string(value)
+		default:
+			return nil, ErrInvalidUploadMetadata
+		}
+	}
+	return metadata, nil
+}
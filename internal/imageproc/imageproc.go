@@ -0,0 +1,144 @@
+package imageproc
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+
+	"github.com/disintegration/imaging"
+)
+
+// Supported output formats.
+const (
+	FormatJPEG = "jpeg"
+	FormatPNG  = "png"
+)
+
+// maxPixels guards against decompression bombs: a small file that declares
+// huge dimensions would otherwise be decoded into gigabytes of memory.
+const maxPixels = 40_000_000
+
+const jpegQuality = 85
+
+// ErrUnsupported is returned for input that is not a decodable JPEG or PNG.
+var ErrUnsupported = errors.New("unsupported image format")
+
+// ErrTooLarge is returned for images whose dimensions exceed maxPixels.
+var ErrTooLarge = errors.New("image dimensions are too large")
+
+// Spec describes one rendition. A zero Height keeps the aspect ratio; Crop
+// fills exactly Width x Height, cutting from the centre. Images are never
+// upscaled.
+type Spec struct {
+	Name   string
+	Width  int
+	Height int
+	Crop   bool
+}
+
+// Rendition is an encoded image and its dimensions.
+type Rendition struct {
+	Name   string
+	Data   []byte
+	Width  int
+	Height int
+}
+
+// Result is the output of Process.
+type Result struct {
+	// Format is FormatJPEG or FormatPNG, matching the input.
+	Format string
+	// Renditions holds one entry per requested Spec, in order.
+	Renditions []Rendition
+}
+
+// Extension returns the file extension for the result format.
+func (r *Result) Extension() string {
+	if r.Format == FormatPNG {
+		return ".png"
+	}
+	return ".jpg"
+}
+
+// ContentType returns the media type for the result format.
+func (r *Result) ContentType() string {
+	if r.Format == FormatPNG {
+		return "image/png"
+	}
+	return "image/jpeg"
+}
+
+// Process decodes a JPEG or PNG, applies its EXIF orientation and encodes
+// every spec. Re-encoding drops all metadata, including EXIF and GPS data.
+func Process(r io.Reader, specs []Spec) (*Result, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image: %w", err)
+	}
+
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || (format != FormatJPEG && format != FormatPNG) {
+		return nil, ErrUnsupported
+	}
+	if cfg.Width*cfg.Height > maxPixels {
+		return nil, fmt.Errorf("%w: %dx%d", ErrTooLarge, cfg.Width, cfg.Height)
+	}
+
+	src, err := imaging.Decode(bytes.NewReader(data), imaging.AutoOrientation(true))
+	if err != nil {
+		return nil, ErrUnsupported
+	}
+
+	result := &Result{Format: format}
+	for _, spec := range specs {
+		img := resize(src, spec)
+
+		var buf bytes.Buffer
+		if format == FormatPNG {
+			err = png.Encode(&buf, img)
+		} else {
+			err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpegQuality})
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode %s rendition: %w", spec.Name, err)
+		}
+
+		bounds := img.Bounds()
+		result.Renditions = append(result.Renditions, Rendition{
+			Name:   spec.Name,
+			Data:   buf.Bytes(),
+			Width:  bounds.Dx(),
+			Height: bounds.Dy(),
+		})
+	}
+
+	return result, nil
+}
+
+func resize(src image.Image, spec Spec) image.Image {
+	bounds := src.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+
+	if spec.Crop && spec.Width > 0 && spec.Height > 0 {
+		width, height := spec.Width, spec.Height
+		if width > w {
+			width = w
+		}
+		if height > h {
+			height = h
+		}
+		return imaging.Fill(src, width, height, imaging.Center, imaging.Lanczos)
+	}
+
+	if spec.Width > 0 && w > spec.Width {
+		return imaging.Resize(src, spec.Width, 0, imaging.Lanczos)
+	}
+	if spec.Height > 0 && h > spec.Height {
+		return imaging.Resize(src, 0, spec.Height, imaging.Lanczos)
+	}
+	return src
+}
@@ -0,0 +1,41 @@
+package imageproc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"testing"
+)
+
+// pngHeader returns a PNG signature and IHDR chunk declaring the given
+// dimensions, which is all DecodeConfig reads.
+func pngHeader(width, height uint32) []byte {
+	ihdr := make([]byte, 13)
+	binary.BigEndian.PutUint32(ihdr[0:], width)
+	binary.BigEndian.PutUint32(ihdr[4:], height)
+	ihdr[8] = 8 // bit depth
+	ihdr[9] = 2 // truecolor
+
+	var buf bytes.Buffer
+	buf.WriteString("\x89PNG\r\n\x1a\n")
+	binary.Write(&buf, binary.BigEndian, uint32(len(ihdr)))
+	chunk := append([]byte("IHDR"), ihdr...)
+	buf.Write(chunk)
+	binary.Write(&buf, binary.BigEndian, crc32.ChecksumIEEE(chunk))
+	return buf.Bytes()
+}
+
+func TestProcessRejectsDecompressionBomb(t *testing.T) {
+	_, err := Process(bytes.NewReader(pngHeader(20000, 20000)), nil)
+	if !errors.Is(err, ErrTooLarge) {
+		t.Fatalf("Process() error = %v, want ErrTooLarge", err)
+	}
+}
+
+func TestProcessRejectsUnsupported(t *testing.T) {
+	_, err := Process(bytes.NewReader([]byte("not an image")), nil)
+	if !errors.Is(err, ErrUnsupported) {
+		t.Fatalf("Process() error = %v, want ErrUnsupported", err)
+	}
+}
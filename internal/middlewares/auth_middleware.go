@@ -4,58 +4,47 @@ import (
 	"strings"
 
 	"go-fiber-boilerplate/config"
-	"go-fiber-boilerplate/database"
-	"go-fiber-boilerplate/internal/models"
+	"go-fiber-boilerplate/internal/services"
+	"go-fiber-boilerplate/pkg/apperror"
 	"go-fiber-boilerplate/utils"
 
 	"github.com/gofiber/fiber/v2"
-	"gorm.io/gorm"
 )
 
-func AuthMiddleware(cfg *config.Config) fiber.Handler {
+func AuthMiddleware(cfg *config.Config, authService *services.AuthService) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		authHeader := c.Get("Authorization")
 		if authHeader == "" {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error": "Authorization header required",
-			})
+			return ErrAuthHeaderRequired
 		}
 
 		tokenParts := strings.Split(authHeader, " ")
 		if len(tokenParts) != 2 || tokenParts[0] != "Bearer" {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error": "Invalid authorization header format",
-			})
+			return ErrInvalidAuthHeader
 		}
 
 		token := tokenParts[1]
 		claims, err := utils.ValidateJWT(token, cfg.JWTSecret, cfg.JWTIssuer, cfg.JWTAudience)
 		if err != nil {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error": "Invalid token",
-			})
+			return ErrInvalidToken
 		}
 
-		var user models.User
-		if err := database.GetDB().First(&user, claims.UserID).Error; err != nil {
-			if err == gorm.ErrRecordNotFound {
-				return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-					"error": "Invalid token",
-				})
-			}
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error": "Unable to validate user",
-			})
+		user, err := authService.SessionUser(claims)
+		if err != nil {
+			return apperror.ErrInternal.Wrap(err)
+		}
+		if user == nil {
+			return ErrInvalidToken
 		}
 
 		if !user.IsActive {
-			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-				"error": "Account is inactive",
-			})
+			return ErrAccountInactive
 		}
 
 		c.Locals("userID", user.ID)
 		c.Locals("email", user.Email)
+		c.Locals("jti", claims.ID)
+		c.Locals("tokenExpiresAt", claims.ExpiresAt.Time)
 
 		return c.Next()
 	}
@@ -0,0 +1,40 @@
+package middlewares
+
+import (
+	"io"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// BodyLimit enforces the app's BodyLimit for requests outside the exempt
+// path prefixes. The app streams request bodies so tus PATCH requests can
+// carry chunks up to TUS_MAX_SIZE, and with streaming on Fiber no longer
+// rejects oversized bodies itself. Every other handler reads the whole body,
+// which is buffered here up to the limit.
+func BodyLimit(exempt ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		for _, prefix := range exempt {
+			if strings.HasPrefix(c.Path(), prefix) {
+				return c.Next()
+			}
+		}
+
+		limit := c.App().Config().BodyLimit
+		req := c.Request()
+		if req.Header.ContentLength() > limit {
+			return ErrBodyTooLarge
+		}
+		if req.IsBodyStream() {
+			body, err := io.ReadAll(io.LimitReader(c.Context().RequestBodyStream(), int64(limit)+1))
+			if err != nil {
+				return fiber.ErrBadRequest
+			}
+			if len(body) > limit {
+				return ErrBodyTooLarge
+			}
+			req.SetBody(body)
+		}
+		return c.Next()
+	}
+}
@@ -0,0 +1,95 @@
+package middlewares
+
+import (
+	"net/netip"
+	"strings"
+
+	"go-fiber-boilerplate/config"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const clientIPKey = "clientip"
+
+// ClientIPMiddleware resolves the client address once per request and stores
+// it for ClientIP. The proxy header is only honoured when the connection
+// comes from a trusted proxy. X-Forwarded-For is read from the right,
+// skipping trusted proxies, so a client cannot pick its address by sending
+// the header itself; any other header is taken as a single address.
+func ClientIPMiddleware(cfg *config.Config) fiber.Handler {
+	trusted := make([]netip.Prefix, 0, len(cfg.TrustedProxies))
+	for _, proxy := range cfg.TrustedProxies {
+		// Validated by config.LoadConfig.
+		if prefix, err := config.ParseProxyPrefix(proxy); err == nil {
+			trusted = append(trusted, prefix)
+		}
+	}
+
+	isTrusted := func(addr netip.Addr) bool {
+		for _, prefix := range trusted {
+			if prefix.Contains(addr) {
+				return true
+			}
+		}
+		return false
+	}
+
+	return func(c *fiber.Ctx) error {
+		remote, _ := netip.AddrFromSlice(c.Context().RemoteIP())
+		remote = remote.Unmap()
+
+		ip := remote
+		if cfg.ProxyHeader != "" && isTrusted(remote) {
+			ip = forwardedIP(c.Get(cfg.ProxyHeader), cfg.ProxyHeader, remote, isTrusted)
+		}
+
+		c.Locals(clientIPKey, ip.String())
+		return c.Next()
+	}
+}
+
+// forwardedIP picks the client address from the proxy header value, falling
+// back to the last address known to be genuine when the value is missing or
+// malformed.
+func forwardedIP(value, header string, remote netip.Addr, isTrusted func(netip.Addr) bool) netip.Addr {
+	if !strings.EqualFold(header, fiber.HeaderXForwardedFor) {
+		if addr, ok := parseForwardedAddr(value); ok {
+			return addr
+		}
+		return remote
+	}
+
+	ip := remote
+	hops := strings.Split(value, ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		addr, ok := parseForwardedAddr(hops[i])
+		if !ok {
+			break
+		}
+		ip = addr
+		if !isTrusted(addr) {
+			break
+		}
+	}
+	return ip
+}
+
+func parseForwardedAddr(value string) (netip.Addr, bool) {
+	value = strings.TrimSpace(value)
+	if addr, err := netip.ParseAddr(value); err == nil {
+		return addr.Unmap(), true
+	}
+	if addrPort, err := netip.ParseAddrPort(value); err == nil {
+		return addrPort.Addr().Unmap(), true
+	}
+	return netip.Addr{}, false
+}
+
+// ClientIP returns the address resolved by ClientIPMiddleware, falling back
+// to the connection's address if it did not run for this request.
+func ClientIP(c *fiber.Ctx) string {
+	if ip, ok := c.Locals(clientIPKey).(string); ok {
+		return ip
+	}
+	return c.IP()
+}
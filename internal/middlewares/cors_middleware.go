@@ -7,12 +7,19 @@ import (
 	"github.com/gofiber/fiber/v2/middleware/cors"
 )
 
+const (
+	tusRequestHeaders        = "Tus-Resumable,Upload-Length,Upload-Offset,Upload-Metadata"
+	rateLimitResponseHeaders = "RateLimit-Limit,RateLimit-Remaining,RateLimit-Reset,Retry-After"
+	tusResponseHeaders       = "Location,Tus-Resumable,Tus-Version,Tus-Extension,Tus-Max-Size,Upload-Offset,Upload-Length,Upload-Expires"
+)
+
 func CORSMiddleware(cfg *config.Config) fiber.Handler {
 	if cfg.AllowCredentials && cfg.AllowedOrigins == "*" {
 		return cors.New(cors.Config{
 			AllowOrigins:     "*",
 			AllowMethods:     "GET,POST,HEAD,PUT,DELETE,PATCH,OPTIONS",
-			AllowHeaders:     "Origin,Content-Type,Accept,Authorization",
+			AllowHeaders:     "Origin,Content-Type,Accept,Authorization,X-Request-ID," + tusRequestHeaders,
+			ExposeHeaders:    "X-Request-ID," + rateLimitResponseHeaders + "," + tusResponseHeaders,
 			AllowCredentials: false,
 		})
 	}
@@ -20,8 +27,8 @@ func CORSMiddleware(cfg *config.Config) fiber.Handler {
 	return cors.New(cors.Config{
 		AllowOrigins:     cfg.AllowedOrigins,
 		AllowMethods:     "GET,POST,HEAD,PUT,DELETE,PATCH,OPTIONS",
-		AllowHeaders:     "Origin,Content-Type,Accept,Authorization",
+		AllowHeaders:     "Origin,Content-Type,Accept,Authorization,X-Request-ID," + tusRequestHeaders,
+		ExposeHeaders:    "X-Request-ID," + rateLimitResponseHeaders + "," + tusResponseHeaders,
 		AllowCredentials: cfg.AllowCredentials,
 	})
 }
-
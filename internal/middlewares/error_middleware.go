@@ -1,22 +1,36 @@
 package middlewares
 
 import (
+	"errors"
 	"log"
 
+	"go-fiber-boilerplate/pkg/apperror"
+
 	"github.com/gofiber/fiber/v2"
 )
 
+// ErrorHandler renders every error returned by a handler or middleware as an
+// RFC 7807 application/problem+json document. *apperror.Error values keep
+// their status, code and public message; a *fiber.Error is mapped by status;
+// anything else is an internal error whose details are only logged.
 func ErrorHandler(c *fiber.Ctx, err error) error {
-	code := fiber.StatusInternalServerError
-
-	if e, ok := err.(*fiber.Error); ok {
-		code = e.Code
+	appErr, ok := apperror.As(err)
+	if !ok {
+		var fiberErr *fiber.Error
+		if errors.As(err, &fiberErr) {
+			appErr = apperror.FromStatus(fiberErr.Code, fiberErr.Message)
+		} else {
+			appErr = apperror.ErrInternal.Wrap(err)
+		}
 	}
 
-	log.Printf("Error: %v", err)
+	requestID := RequestID(c)
+	if appErr.Status >= fiber.StatusInternalServerError {
+		log.Printf("Error [%s] %s: %v", requestID, ClientIP(c), err)
+	}
 
-	return c.Status(code).JSON(fiber.Map{
-		"error":   true,
-		"message": "internal server error",
-	})
+	return c.Status(appErr.Status).JSON(
+		appErr.Problem(c.Path(), requestID),
+		apperror.ProblemContentType,
+	)
 }
@@ -0,0 +1,24 @@
+package middlewares
+
+import (
+	"net/http"
+
+	"go-fiber-boilerplate/pkg/apperror"
+)
+
+// Errors returned by the middlewares and rendered by ErrorHandler.
+var (
+	ErrAuthHeaderRequired    = apperror.New("authorization_required", http.StatusUnauthorized, "Authorization header required")
+	ErrInvalidAuthHeader     = apperror.New("invalid_authorization_header", http.StatusUnauthorized, "Invalid authorization header format")
+	ErrInvalidToken          = apperror.New("invalid_token", http.StatusUnauthorized, "Invalid token")
+	ErrAccountInactive       = apperror.New("account_inactive", http.StatusForbidden, "Account is inactive")
+	ErrPermissionDenied      = apperror.New("permission_denied", http.StatusForbidden, "You don't have permission to perform this action")
+	ErrNoFileUploaded        = apperror.New("no_file_uploaded", http.StatusBadRequest, "No file uploaded")
+	ErrFileTooLarge          = apperror.New("file_too_large", http.StatusBadRequest, "File too large")
+	ErrFileTypeNotAllowed    = apperror.New("file_type_not_allowed", http.StatusBadRequest, "File type is not allowed")
+	ErrInvalidFileExt        = apperror.New("invalid_file_extension", http.StatusBadRequest, "Invalid file extension")
+	ErrUploadUnreadable      = apperror.New("upload_unreadable", http.StatusInternalServerError, "Failed to read file")
+	ErrRateLimitUnavailable  = apperror.New("rate_limit_unavailable", http.StatusServiceUnavailable, "Service temporarily unavailable, please retry later")
+	ErrUnsupportedTusVersion = apperror.New("unsupported_tus_version", http.StatusPreconditionFailed, "Unsupported Tus-Resumable version")
+	ErrBodyTooLarge          = apperror.New("body_too_large", http.StatusRequestEntityTooLarge, "Request body too large")
+)
@@ -1,72 +1,53 @@
 package middlewares
 
 import (
-	"sync"
+	"log"
+	"strconv"
 	"time"
 
+	"go-fiber-boilerplate/internal/ratelimit"
+	"go-fiber-boilerplate/pkg/apperror"
+
 	"github.com/gofiber/fiber/v2"
 )
 
-type rateLimiter struct {
-	mu          sync.Mutex
-	requests    map[string]*rateRecord
-	maxRequests int
-	window      time.Duration
-}
-
-type rateRecord struct {
-	count     int
-	expiresAt time.Time
-}
-
-func newRateLimiter(max int, window time.Duration) *rateLimiter {
-	return &rateLimiter{
-		requests:    make(map[string]*rateRecord),
-		maxRequests: max,
-		window:      window,
-	}
-}
-
-func (r *rateLimiter) allow(key string) bool {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-
-	now := time.Now()
-	rec, exists := r.requests[key]
-	if !exists || now.After(rec.expiresAt) {
-		if exists {
-			delete(r.requests, key)
-		}
-		r.requests[key] = &rateRecord{
-			count:     1,
-			expiresAt: now.Add(r.window),
-		}
-		return true
-	}
-
-	if rec.count >= r.maxRequests {
-		return false
-	}
-
-	rec.count++
-	return true
-}
-
-func RateLimitMiddleware(max int, window time.Duration, keyFunc func(*fiber.Ctx) string) fiber.Handler {
-	rl := newRateLimiter(max, window)
-
+// RateLimitMiddleware applies limit to each key returned by keyFunc (the
+// client IP when it returns ""). State is kept in store and scoped to the
+// route, so routes sharing a store are limited independently. Responses
+// carry the RateLimit-Limit, RateLimit-Remaining and RateLimit-Reset headers,
+// and rejections Retry-After. When the store fails the request is let
+// through, or rejected with 503 if limit.FailClosed is set.
+func RateLimitMiddleware(store ratelimit.Store, limit ratelimit.Limit, keyFunc func(*fiber.Ctx) string) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		key := keyFunc(c)
 		if key == "" {
-			key = c.IP()
+			key = ClientIP(c)
+		}
+		key = c.Method() + ":" + c.Route().Path + ":" + key
+
+		result, err := store.Allow(c.UserContext(), key, limit)
+		if err != nil {
+			log.Printf("[%s] %s rate limit store unavailable: %v", RequestID(c), ClientIP(c), err)
+			if limit.FailClosed {
+				return ErrRateLimitUnavailable
+			}
+			return c.Next()
 		}
 
-		if !rl.allow(key) {
-			return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
-				"error": "Too many requests",
-			})
+		c.Set("RateLimit-Limit", strconv.Itoa(result.Limit))
+		c.Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		c.Set("RateLimit-Reset", strconv.FormatInt(ceilSeconds(result.Reset), 10))
+		if !result.Allowed {
+			c.Set(fiber.HeaderRetryAfter, strconv.FormatInt(max(ceilSeconds(result.RetryAfter), 1), 10))
+			return apperror.ErrTooManyRequests
 		}
 
 		return c.Next()
 	}
 }
+
+// ceilSeconds rounds d up to whole seconds, the unit of the rate limit
+// headers.
+func ceilSeconds(d time.Duration) int64 {
+	return int64((d + time.Second - 1) / time.Second)
+}
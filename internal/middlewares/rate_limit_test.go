@@ -0,0 +1,54 @@
+package middlewares
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go-fiber-boilerplate/internal/ratelimit"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// unavailableStore is a ratelimit.Store whose backend cannot be reached.
+type unavailableStore struct{}
+
+func (unavailableStore) Allow(context.Context, string, ratelimit.Limit) (ratelimit.Result, error) {
+	return ratelimit.Result{}, errors.New("connection refused")
+}
+
+func (unavailableStore) Close() error { return nil }
+
+func TestRateLimitMiddlewareStoreUnavailable(t *testing.T) {
+	tests := []struct {
+		name       string
+		failClosed bool
+		want       int
+	}{
+		{"fail open", false, fiber.StatusOK},
+		{"fail closed", true, fiber.StatusServiceUnavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+			app.Get("/", RateLimitMiddleware(unavailableStore{}, ratelimit.Limit{
+				Algorithm:  ratelimit.SlidingWindowLog,
+				Max:        1,
+				Window:     time.Minute,
+				FailClosed: tt.failClosed,
+			}, ClientIP), func(c *fiber.Ctx) error {
+				return c.SendStatus(fiber.StatusOK)
+			})
+
+			resp, err := app.Test(httptest.NewRequest("GET", "/", nil))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.StatusCode != tt.want {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package middlewares
+
+import (
+	"go-fiber-boilerplate/internal/services"
+	"go-fiber-boilerplate/pkg/apperror"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RequirePermission allows the request only when the authenticated user holds
+// every given permission. It must run after AuthMiddleware. The loaded
+// permissions are kept in c.Locals("permissions") for later handlers.
+func RequirePermission(rbacService *services.RBACService, permissions ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID, ok := c.Locals("userID").(uint)
+		if !ok {
+			return apperror.ErrUnauthorized.WithMessage("Authentication required")
+		}
+
+		granted, ok := c.Locals("permissions").(map[string]bool)
+		if !ok {
+			var err error
+			granted, err = rbacService.UserPermissions(userID)
+			if err != nil {
+				return apperror.ErrInternal.Wrap(err)
+			}
+			c.Locals("permissions", granted)
+		}
+
+		for _, permission := range permissions {
+			if !granted[permission] {
+				return ErrPermissionDenied
+			}
+		}
+
+		return c.Next()
+	}
+}
@@ -0,0 +1,39 @@
+package middlewares
+
+import (
+	"regexp"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+)
+
+const requestIDKey = "requestid"
+
+// validRequestID is the shape a client supplied X-Request-ID must have to be
+// reused. It ends up in logs and error bodies, so anything longer or with
+// other characters is replaced.
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// RequestIDMiddleware assigns every request an ID, reusing a valid client
+// supplied X-Request-ID header, and echoes it in the response.
+func RequestIDMiddleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id := c.Get(fiber.HeaderXRequestID)
+		if validRequestID.MatchString(id) {
+			id = utils.CopyString(id)
+		} else {
+			id = utils.UUIDv4()
+		}
+
+		c.Set(fiber.HeaderXRequestID, id)
+		c.Locals(requestIDKey, id)
+		return c.Next()
+	}
+}
+
+// RequestID returns the ID assigned by RequestIDMiddleware, or "" if it did
+// not run for this request.
+func RequestID(c *fiber.Ctx) string {
+	id, _ := c.Locals(requestIDKey).(string)
+	return id
+}
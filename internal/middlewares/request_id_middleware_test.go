@@ -0,0 +1,49 @@
+package middlewares
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestRequestIDMiddleware(t *testing.T) {
+	app := fiber.New()
+	app.Use(RequestIDMiddleware())
+	app.Get("/", func(c *fiber.Ctx) error {
+		return c.SendString(RequestID(c))
+	})
+
+	tests := []struct {
+		name   string
+		header string
+		reused bool
+	}{
+		{"valid", "abc-123_x.y", true},
+		{"missing", "", false},
+		{"too long", strings.Repeat("a", 65), false},
+		{"log injection", "abc\r\nlevel=admin", false},
+		{"spaces", "abc def", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			if tt.header != "" {
+				req.Header.Set(fiber.HeaderXRequestID, tt.header)
+			}
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			id := resp.Header.Get(fiber.HeaderXRequestID)
+			if tt.reused && id != tt.header {
+				t.Errorf("X-Request-ID = %q, want %q", id, tt.header)
+			}
+			if !tt.reused && (id == tt.header || !validRequestID.MatchString(id)) {
+				t.Errorf("X-Request-ID = %q, want a generated ID", id)
+			}
+		})
+	}
+}
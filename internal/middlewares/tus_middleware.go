@@ -0,0 +1,26 @@
+package middlewares
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+// TusVersion is the tus protocol version served by the upload endpoints.
+const TusVersion = "1.0.0"
+
+// TusResumable adds the Tus-Resumable header to every response and rejects
+// requests for another protocol version. OPTIONS requests are used for
+// version discovery and are always let through.
+func TusResumable() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		c.Set("Tus-Resumable", TusVersion)
+
+		if c.Method() == fiber.MethodOptions {
+			return c.Next()
+		}
+		if c.Get("Tus-Resumable") != TusVersion {
+			c.Set("Tus-Version", TusVersion)
+			return ErrUnsupportedTusVersion
+		}
+		return c.Next()
+	}
+}
@@ -2,12 +2,14 @@ package middlewares
 
 import (
 	"errors"
+	"mime/multipart"
 	"net/http"
 	"path/filepath"
 	"strconv"
 	"strings"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/valyala/fasthttp"
 )
 
 type UploaderMiddleware struct{}
@@ -18,21 +20,17 @@ func NewUploaderMiddleware() *UploaderMiddleware {
 
 func (u *UploaderMiddleware) Upload(maxSizeMB int) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		file, err := c.FormFile("image")
+		file, err := imageFile(c)
 		if err != nil {
-			if errors.Is(err, http.ErrMissingFile) {
-				return c.Next()
-			}
-			return c.Status(400).JSON(fiber.Map{
-				"error": "No file uploaded",
-			})
+			return err
+		}
+		if file == nil {
+			return c.Next()
 		}
 
 		maxSize := int64(maxSizeMB * 1024 * 1024)
 		if file.Size > maxSize {
-			return c.Status(400).JSON(fiber.Map{
-				"error": "File too large (max " + strconv.Itoa(maxSizeMB) + "MB)",
-			})
+			return ErrFileTooLarge.WithMessage("File too large (max " + strconv.Itoa(maxSizeMB) + "MB)")
 		}
 
 		c.Locals("uploadedFile", file)
@@ -42,30 +40,24 @@ func (u *UploaderMiddleware) Upload(maxSizeMB int) fiber.Handler {
 
 func (u *UploaderMiddleware) FileFilter(allowedTypes []string) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		file, err := c.FormFile("image")
+		file, err := imageFile(c)
 		if err != nil {
-			if errors.Is(err, http.ErrMissingFile) {
-				return c.Next()
-			}
-			return c.Status(400).JSON(fiber.Map{
-				"error": "No file uploaded",
-			})
+			return err
+		}
+		if file == nil {
+			return c.Next()
 		}
 
 		src, err := file.Open()
 		if err != nil {
-			return c.Status(500).JSON(fiber.Map{
-				"error": "Failed to open file",
-			})
+			return ErrUploadUnreadable.WithMessage("Failed to open file").Wrap(err)
 		}
 		defer src.Close()
 
 		buffer := make([]byte, 512)
 		_, err = src.Read(buffer)
 		if err != nil {
-			return c.Status(500).JSON(fiber.Map{
-				"error": "Failed to read file",
-			})
+			return ErrUploadUnreadable.Wrap(err)
 		}
 
 		mimeType := http.DetectContentType(buffer)
@@ -79,9 +71,7 @@ func (u *UploaderMiddleware) FileFilter(allowedTypes []string) fiber.Handler {
 		}
 
 		if !isAllowed {
-			return c.Status(400).JSON(fiber.Map{
-				"error": "File type " + mimeType + " is not allowed",
-			})
+			return ErrFileTypeNotAllowed.WithMessage("File type " + mimeType + " is not allowed")
 		}
 
 		ext := strings.ToLower(filepath.Ext(file.Filename))
@@ -95,9 +85,7 @@ func (u *UploaderMiddleware) FileFilter(allowedTypes []string) fiber.Handler {
 		}
 
 		if !isValidExt {
-			return c.Status(400).JSON(fiber.Map{
-				"error": "Invalid file extension",
-			})
+			return ErrInvalidFileExt
 		}
 
 		c.Locals("uploadedFile", file)
@@ -106,6 +94,19 @@ func (u *UploaderMiddleware) FileFilter(allowedTypes []string) fiber.Handler {
 	}
 }
 
+// imageFile returns the "image" form file, or nil when the request carries
+// none, including requests that are not multipart at all.
+func imageFile(c *fiber.Ctx) (*multipart.FileHeader, error) {
+	file, err := c.FormFile("image")
+	if err != nil {
+		if errors.Is(err, fasthttp.ErrMissingFile) || errors.Is(err, fasthttp.ErrNoMultipartForm) {
+			return nil, nil
+		}
+		return nil, ErrNoFileUploaded
+	}
+	return file, nil
+}
+
 func (u *UploaderMiddleware) getExtensionsFromMimeTypes(mimeTypes []string) []string {
 	extMap := map[string][]string{
 		"image/jpeg": {".jpg", ".jpeg"},
@@ -127,37 +128,29 @@ func (u *UploaderMiddleware) getExtensionsFromMimeTypes(mimeTypes []string) []st
 
 func (u *UploaderMiddleware) ImageUpload(maxSizeMB int, allowedTypes []string) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		file, err := c.FormFile("image")
+		file, err := imageFile(c)
 		if err != nil {
-			if errors.Is(err, http.ErrMissingFile) {
-				return c.Next()
-			}
-			return c.Status(400).JSON(fiber.Map{
-				"error": "No file uploaded",
-			})
+			return err
+		}
+		if file == nil {
+			return c.Next()
 		}
 
 		maxSize := int64(maxSizeMB * 1024 * 1024)
 		if file.Size > maxSize {
-			return c.Status(400).JSON(fiber.Map{
-				"error": "File too large (max " + strconv.Itoa(maxSizeMB) + "MB)",
-			})
+			return ErrFileTooLarge.WithMessage("File too large (max " + strconv.Itoa(maxSizeMB) + "MB)")
 		}
 
 		src, err := file.Open()
 		if err != nil {
-			return c.Status(500).JSON(fiber.Map{
-				"error": "Failed to open file",
-			})
+			return ErrUploadUnreadable.WithMessage("Failed to open file").Wrap(err)
 		}
 		defer src.Close()
 
 		buffer := make([]byte, 512)
 		_, err = src.Read(buffer)
 		if err != nil {
-			return c.Status(500).JSON(fiber.Map{
-				"error": "Failed to read file",
-			})
+			return ErrUploadUnreadable.Wrap(err)
 		}
 
 		mimeType := http.DetectContentType(buffer)
@@ -171,9 +164,7 @@ func (u *UploaderMiddleware) ImageUpload(maxSizeMB int, allowedTypes []string) f
 		}
 
 		if !isAllowed {
-			return c.Status(400).JSON(fiber.Map{
-				"error": "File type " + mimeType + " is not allowed",
-			})
+			return ErrFileTypeNotAllowed.WithMessage("File type " + mimeType + " is not allowed")
 		}
 
 		ext := strings.ToLower(filepath.Ext(file.Filename))
@@ -187,9 +178,7 @@ func (u *UploaderMiddleware) ImageUpload(maxSizeMB int, allowedTypes []string) f
 		}
 
 		if !isValidExt {
-			return c.Status(400).JSON(fiber.Map{
-				"error": "Invalid file extension",
-			})
+			return ErrInvalidFileExt
 		}
 
 		c.Locals("uploadedFile", file)
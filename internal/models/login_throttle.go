@@ -0,0 +1,19 @@
+package models
+
+import (
+	"time"
+)
+
+// LoginThrottle counts recent failed logins for one email address. Rows are
+// keyed by a hash of the normalised address and kept whether or not an
+// account exists for it, so throttling reveals nothing about which addresses
+// are registered. No login is accepted for the address before LockedUntil.
+type LoginThrottle struct {
+	ID             uint       `json:"id" gorm:"primaryKey"`
+	EmailHash      string     `json:"-" gorm:"size:64;uniqueIndex;not null"`
+	FailedAttempts int        `json:"failed_attempts" gorm:"not null;default:0"`
+	LastFailedAt   time.Time  `json:"last_failed_at" gorm:"index"`
+	LockedUntil    *time.Time `json:"locked_until"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
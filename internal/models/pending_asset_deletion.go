@@ -0,0 +1,17 @@
+package models
+
+import (
+	"time"
+)
+
+// PendingAssetDeletion records a stored asset whose deletion failed. The
+// reconciliation job retries it with exponential backoff until it succeeds.
+type PendingAssetDeletion struct {
+	ID            uint      `json:"id" gorm:"primaryKey"`
+	PublicID      string    `json:"public_id" gorm:"size:512;not null;uniqueIndex"`
+	Attempts      int       `json:"attempts" gorm:"not null;default:0"`
+	LastError     string    `json:"last_error"`
+	NextAttemptAt time.Time `json:"next_attempt_at" gorm:"not null;index"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
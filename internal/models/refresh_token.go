@@ -0,0 +1,19 @@
+package models
+
+import (
+	"time"
+)
+
+// RefreshToken is a single-use, hashed refresh token. Tokens issued by
+// rotating one another share a FamilyID so the whole chain can be revoked
+// when an already used token is presented again.
+type RefreshToken struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	UserID    uint       `json:"user_id" gorm:"index;not null"`
+	FamilyID  string     `json:"family_id" gorm:"index;not null"`
+	TokenHash string     `json:"-" gorm:"uniqueIndex;not null"`
+	ExpiresAt time.Time  `json:"expires_at" gorm:"not null"`
+	UsedAt    *time.Time `json:"used_at"`
+	RevokedAt *time.Time `json:"revoked_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
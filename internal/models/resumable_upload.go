@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+)
+
+// ResumableUpload tracks a tus upload. The received bytes live in a partial
+// file under TUS_UPLOAD_DIR until the upload is attached to a resource.
+type ResumableUpload struct {
+	ID          string     `json:"id" gorm:"primaryKey;size:64"`
+	UserID      uint       `json:"user_id" gorm:"not null;index"`
+	Filename    string     `json:"filename"`
+	FileType    string     `json:"filetype"`
+	Length      int64      `json:"length" gorm:"column:upload_length;not null"`
+	Offset      int64      `json:"offset" gorm:"column:upload_offset;not null;default:0"`
+	CompletedAt *time.Time `json:"completed_at"`
+	ExpiresAt   time.Time  `json:"expires_at" gorm:"not null;index"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+func (u *ResumableUpload) IsComplete() bool {
+	return u.CompletedAt != nil
+}
+
+type AttachUploadRequest struct {
+	UploadID string `json:"upload_id" validate:"required"`
+}
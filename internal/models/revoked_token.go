@@ -0,0 +1,15 @@
+package models
+
+import (
+	"time"
+)
+
+// RevokedToken records the jti of an access token that was logged out before
+// it expired. Rows can be pruned once ExpiresAt has passed.
+type RevokedToken struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	JTI       string    `json:"jti" gorm:"column:jti;uniqueIndex;not null"`
+	UserID    uint      `json:"user_id" gorm:"index;not null"`
+	ExpiresAt time.Time `json:"expires_at" gorm:"index;not null"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,92 @@
+package models
+
+import (
+	"time"
+)
+
+const (
+	RoleAdmin     = "admin"
+	RoleModerator = "moderator"
+	RoleUser      = "user"
+)
+
+const (
+	PermSamplesUpdateAny = "samples:update:any"
+	PermSamplesDeleteAny = "samples:delete:any"
+	PermUsersRead        = "users:read"
+	PermUsersManage      = "users:manage"
+	PermRolesManage      = "roles:manage"
+	PermSystemRead       = "system:read"
+)
+
+// DefaultPermissions are created on startup by database.SeedRoles once the
+// migrations are applied.
+var DefaultPermissions = map[string]string{
+	PermSamplesUpdateAny: "Update samples owned by any user",
+	PermSamplesDeleteAny: "Delete samples owned by any user",
+	PermUsersRead:        "View user accounts",
+	PermUsersManage:      "Activate, deactivate and delete user accounts",
+	PermRolesManage:      "Assign roles to users",
+	PermSystemRead:       "View operational metrics",
+}
+
+// DefaultRoles maps each seeded role to the permissions it is granted.
+// RoleUser is assigned to every new account and carries no extra permission;
+// owners can always manage their own resources.
+var DefaultRoles = map[string][]string{
+	RoleAdmin: {
+		PermSamplesUpdateAny,
+		PermSamplesDeleteAny,
+		PermUsersRead,
+		PermUsersManage,
+		PermRolesManage,
+		PermSystemRead,
+	},
+	RoleModerator: {
+		PermSamplesUpdateAny,
+		PermSamplesDeleteAny,
+		PermUsersRead,
+	},
+	RoleUser: {},
+}
+
+type Role struct {
+	ID          uint         `json:"id" gorm:"primaryKey"`
+	Name        string       `json:"name" gorm:"uniqueIndex;not null"`
+	Description string       `json:"description"`
+	Permissions []Permission `json:"permissions" gorm:"many2many:role_permissions"`
+	CreatedAt   time.Time    `json:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+}
+
+type Permission struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	Name        string    `json:"name" gorm:"uniqueIndex;not null"`
+	Description string    `json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type RoleResponse struct {
+	ID          uint     `json:"id"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+}
+
+type SetUserRolesRequest struct {
+	Roles []string `json:"roles" validate:"required,min=1,dive,notblank"`
+}
+
+func (r *Role) ToResponse() RoleResponse {
+	permissions := make([]string, 0, len(r.Permissions))
+	for _, p := range r.Permissions {
+		permissions = append(permissions, p.Name)
+	}
+
+	return RoleResponse{
+		ID:          r.ID,
+		Name:        r.Name,
+		Description: r.Description,
+		Permissions: permissions,
+	}
+}
@@ -14,20 +14,22 @@ type Sample struct {
 	ImagePublicID string         `json:"-" gorm:"column:image_public_id"`
 	UserID        uint           `json:"userId" gorm:"not null"`
 	User          User           `json:"user" gorm:"foreignKey:UserID"`
+	Images        []SampleImage  `json:"images" gorm:"foreignKey:SampleID"`
 	CreatedAt     time.Time      `json:"createdAt"`
 	UpdatedAt     time.Time      `json:"updatedAt"`
 	DeletedAt     gorm.DeletedAt `json:"deletedAt,omitempty" gorm:"index"`
 }
 
 type SampleResponse struct {
-	ID          uint         `json:"id"`
-	Title       string       `json:"title"`
-	Description string       `json:"description"`
-	ImageURL    string       `json:"imageUrl"`
-	UserID      uint         `json:"userId"`
-	User        UserResponse `json:"user"`
-	CreatedAt   time.Time    `json:"createdAt"`
-	UpdatedAt   time.Time    `json:"updatedAt"`
+	ID          uint                  `json:"id"`
+	Title       string                `json:"title"`
+	Description string                `json:"description"`
+	ImageURL    string                `json:"imageUrl"`
+	Images      []SampleImageResponse `json:"images"`
+	UserID      uint                  `json:"userId"`
+	User        UserResponse          `json:"user"`
+	CreatedAt   time.Time             `json:"createdAt"`
+	UpdatedAt   time.Time             `json:"updatedAt"`
 }
 
 type SamplePublicResponse struct {
@@ -41,21 +43,27 @@ type SamplePublicResponse struct {
 }
 
 type CreateSampleRequest struct {
-	Title       string `json:"title" validate:"required"`
-	Description string `json:"description"`
+	Title       string `json:"title" form:"title" validate:"notblank"`
+	Description string `json:"description" form:"description"`
 }
 
 type UpdateSampleRequest struct {
-	Title       string `json:"title"`
-	Description string `json:"description"`
+	Title       string `json:"title" form:"title"`
+	Description string `json:"description" form:"description"`
 }
 
 func (s *Sample) ToResponse() SampleResponse {
+	images := make([]SampleImageResponse, 0, len(s.Images))
+	for _, image := range s.Images {
+		images = append(images, image.ToResponse())
+	}
+
 	return SampleResponse{
 		ID:          s.ID,
 		Title:       s.Title,
 		Description: s.Description,
 		ImageURL:    s.ImageURL,
+		Images:      images,
 		UserID:      s.UserID,
 		User:        s.User.ToResponse(),
 		CreatedAt:   s.CreatedAt,
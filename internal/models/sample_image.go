@@ -0,0 +1,69 @@
+package models
+
+import (
+	"time"
+)
+
+// SampleImage is one image in a sample's gallery, ordered by Position. The
+// image at position 0 is the cover mirrored in Sample.ImageURL.
+type SampleImage struct {
+	ID        uint           `json:"id" gorm:"primaryKey"`
+	SampleID  uint           `json:"sampleId" gorm:"not null;index"`
+	Position  int            `json:"position" gorm:"not null;default:0"`
+	URL       string         `json:"url" gorm:"not null"`
+	PublicID  string         `json:"-" gorm:"column:public_id;not null"`
+	AltText   string         `json:"altText"`
+	Width     int            `json:"width"`
+	Height    int            `json:"height"`
+	Variants  []ImageVariant `json:"variants" gorm:"serializer:json;not null"`
+	CreatedAt time.Time      `json:"createdAt"`
+	UpdatedAt time.Time      `json:"updatedAt"`
+}
+
+// ImageVariant is a rendition of a gallery image as reported by storage when
+// it was stored. Images stored before variants were recorded have none.
+type ImageVariant struct {
+	Name   string `json:"name"`
+	URL    string `json:"url"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+}
+
+type SampleImageResponse struct {
+	ID       uint           `json:"id"`
+	Position int            `json:"position"`
+	URL      string         `json:"url"`
+	AltText  string         `json:"altText"`
+	Width    int            `json:"width"`
+	Height   int            `json:"height"`
+	Variants []ImageVariant `json:"variants"`
+}
+
+// SampleImageRequest carries the optional fields sent with a gallery image,
+// either as multipart form fields next to the "image" file or as JSON when
+// the image comes from a resumable upload.
+type SampleImageRequest struct {
+	AltText  string `json:"alt_text" form:"alt_text" validate:"max=255"`
+	UploadID string `json:"upload_id" form:"upload_id"`
+}
+
+type ReorderSampleImagesRequest struct {
+	ImageIDs []uint `json:"image_ids" validate:"required,min=1"`
+}
+
+func (i *SampleImage) ToResponse() SampleImageResponse {
+	variants := i.Variants
+	if variants == nil {
+		variants = []ImageVariant{}
+	}
+
+	return SampleImageResponse{
+		ID:       i.ID,
+		Position: i.Position,
+		URL:      i.URL,
+		AltText:  i.AltText,
+		Width:    i.Width,
+		Height:   i.Height,
+		Variants: variants,
+	}
+}
@@ -7,32 +7,50 @@ import (
 )
 
 type User struct {
-	ID        uint           `json:"id" gorm:"primaryKey"`
-	Email     string         `json:"email" gorm:"uniqueIndex;not null"`
-	Password  string         `json:"-" gorm:"not null"`
-	FirstName string         `json:"first_name" gorm:"not null"`
-	LastName  string         `json:"last_name" gorm:"not null"`
-	IsActive  bool           `json:"is_active" gorm:"default:true"`
-	CreatedAt time.Time      `json:"created_at"`
-	UpdatedAt time.Time      `json:"updated_at"`
-	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+	ID              uint           `json:"id" gorm:"primaryKey"`
+	Email           string         `json:"email" gorm:"uniqueIndex;not null"`
+	Password        string         `json:"-" gorm:"not null"`
+	FirstName       string         `json:"first_name" gorm:"not null"`
+	LastName        string         `json:"last_name" gorm:"not null"`
+	IsActive        bool           `json:"is_active" gorm:"default:true"`
+	TokenVersion    int            `json:"-" gorm:"not null;default:0"`
+	EmailVerifiedAt *time.Time     `json:"email_verified_at"`
+	TOTPSecret      string         `json:"-" gorm:"column:totp_secret"`
+	TOTPEnabled     bool           `json:"totp_enabled" gorm:"column:totp_enabled;default:false"`
+	TOTPLastStep    int64          `json:"-" gorm:"column:totp_last_step;default:0"`
+	Roles           []Role         `json:"roles,omitempty" gorm:"many2many:user_roles"`
+	CreatedAt       time.Time      `json:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at"`
+	DeletedAt       gorm.DeletedAt `json:"-" gorm:"index"`
 }
 
 type UserResponse struct {
-	ID        uint      `json:"id"`
-	Email     string    `json:"email"`
-	FirstName string    `json:"first_name"`
-	LastName  string    `json:"last_name"`
-	IsActive  bool      `json:"is_active"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID              uint       `json:"id"`
+	Email           string     `json:"email"`
+	FirstName       string     `json:"first_name"`
+	LastName        string     `json:"last_name"`
+	IsActive        bool       `json:"is_active"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
+	MFAEnabled      bool       `json:"mfa_enabled"`
+	Roles           []string   `json:"roles,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+type AdminUserResponse struct {
+	UserResponse
+	DeletedAt *time.Time `json:"deleted_at"`
+}
+
+type UpdateUserStatusRequest struct {
+	IsActive *bool `json:"is_active" validate:"required"`
 }
 
 type CreateUserRequest struct {
 	Email     string `json:"email" validate:"required,email"`
-	Password  string `json:"password" validate:"required,min=6"`
-	FirstName string `json:"first_name" validate:"required"`
-	LastName  string `json:"last_name" validate:"required"`
+	Password  string `json:"password" validate:"required,password"`
+	FirstName string `json:"first_name" validate:"notblank"`
+	LastName  string `json:"last_name" validate:"notblank"`
 }
 
 type RegisterResponse struct {
@@ -45,8 +63,32 @@ type LoginRequest struct {
 }
 
 type LoginResponse struct {
-	Token string       `json:"token"`
-	User  UserResponse `json:"user"`
+	Token        string        `json:"token,omitempty"`
+	RefreshToken string        `json:"refresh_token,omitempty"`
+	ExpiresIn    int64         `json:"expires_in"`
+	MFARequired  bool          `json:"mfa_required"`
+	MFAToken     string        `json:"mfa_token,omitempty"`
+	User         *UserResponse `json:"user,omitempty"`
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+type UnlockAccountRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+type ResendVerificationRequest struct {
+	Email string `json:"email" validate:"required,email"`
 }
 
 type ForgotPasswordRequest struct {
@@ -55,17 +97,34 @@ type ForgotPasswordRequest struct {
 
 type ResetPasswordRequest struct {
 	Token       string `json:"token" validate:"required"`
-	NewPassword string `json:"new_password" validate:"required,min=6"`
+	NewPassword string `json:"new_password" validate:"required,password"`
 }
 
 func (u *User) ToResponse() UserResponse {
+	var roles []string
+	for _, role := range u.Roles {
+		roles = append(roles, role.Name)
+	}
+
 	return UserResponse{
-		ID:        u.ID,
-		Email:     u.Email,
-		FirstName: u.FirstName,
-		LastName:  u.LastName,
-		IsActive:  u.IsActive,
-		CreatedAt: u.CreatedAt,
-		UpdatedAt: u.UpdatedAt,
+		ID:              u.ID,
+		Email:           u.Email,
+		FirstName:       u.FirstName,
+		LastName:        u.LastName,
+		IsActive:        u.IsActive,
+		EmailVerifiedAt: u.EmailVerifiedAt,
+		MFAEnabled:      u.TOTPEnabled,
+		Roles:           roles,
+		CreatedAt:       u.CreatedAt,
+		UpdatedAt:       u.UpdatedAt,
+	}
+}
+
+func (u *User) ToAdminResponse() AdminUserResponse {
+	response := AdminUserResponse{UserResponse: u.ToResponse()}
+	if u.DeletedAt.Valid {
+		deletedAt := u.DeletedAt.Time
+		response.DeletedAt = &deletedAt
 	}
+	return response
 }
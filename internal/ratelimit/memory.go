@@ -0,0 +1,280 @@
+package ratelimit
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// Memory keeps rate limit state in process memory. It is lost on restart
+// and not shared between replicas.
+//
+// Memory use is bounded: a sweeper goroutine drops entries once they no
+// longer affect their limit, and when more than maxKeys are tracked the least
+// recently used key is evicted, which only resets that client's quota early.
+// Close stops the sweeper.
+type Memory struct {
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	lru     *list.List // of *memoryEntry, most recently used first
+	maxKeys int
+
+	evictions   uint64
+	expirations uint64
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// memoryEntry holds the state of one key. Each algorithm uses its own
+// fields; keys are prefixed with the algorithm so they never mix.
+type memoryEntry struct {
+	key string
+	// expiresAt is when the entry is back to its initial state and can be
+	// dropped.
+	expiresAt time.Time
+	// FixedWindow and SlidingWindowCounter.
+	windowStart time.Time
+	count       int
+	previous    int
+	// SlidingWindowLog, oldest first.
+	hits []time.Time
+	// TokenBucket.
+	tokens    float64
+	updatedAt time.Time
+}
+
+// Stats describes what a Memory store is tracking.
+type Stats struct {
+	Keys        int    `json:"keys"`
+	MaxKeys     int    `json:"max_keys"`
+	Evictions   uint64 `json:"evictions"`
+	Expirations uint64 `json:"expirations"`
+}
+
+// NewMemory returns a store tracking at most maxKeys keys whose expired
+// entries are swept every sweepInterval.
+func NewMemory(maxKeys int, sweepInterval time.Duration) *Memory {
+	m := &Memory{
+		entries: make(map[string]*list.Element),
+		lru:     list.New(),
+		maxKeys: maxKeys,
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	go m.sweep(sweepInterval)
+	return m
+}
+
+func (m *Memory) Allow(ctx context.Context, key string, limit Limit) (Result, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	entry := m.entry(string(limit.Algorithm)+":"+key, now)
+
+	switch limit.Algorithm {
+	case FixedWindow:
+		return entry.fixedWindow(now, limit), nil
+	case SlidingWindowLog:
+		return entry.slidingWindowLog(now, limit), nil
+	case SlidingWindowCounter:
+		return entry.slidingWindowCounter(now, limit), nil
+	case TokenBucket:
+		return entry.tokenBucket(now, limit), nil
+	default:
+		return Result{}, fmt.Errorf("unknown rate limit algorithm %q", limit.Algorithm)
+	}
+}
+
+// Stats reports the number of tracked keys and how many were dropped.
+func (m *Memory) Stats() Stats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return Stats{
+		Keys:        m.lru.Len(),
+		MaxKeys:     m.maxKeys,
+		Evictions:   m.evictions,
+		Expirations: m.expirations,
+	}
+}
+
+// Close stops the sweeper and waits for it to exit. It is safe to call more
+// than once.
+func (m *Memory) Close() error {
+	select {
+	case <-m.stop:
+	default:
+		close(m.stop)
+	}
+	<-m.done
+	return nil
+}
+
+// entry returns the entry for key, creating it and evicting the least
+// recently used entries if needed. An expired entry is reset.
+func (m *Memory) entry(key string, now time.Time) *memoryEntry {
+	if element, ok := m.entries[key]; ok {
+		m.lru.MoveToFront(element)
+		entry := element.Value.(*memoryEntry)
+		if !now.Before(entry.expiresAt) {
+			*entry = memoryEntry{key: key}
+		}
+		return entry
+	}
+
+	for m.lru.Len() >= m.maxKeys {
+		oldest := m.lru.Back()
+		m.lru.Remove(oldest)
+		delete(m.entries, oldest.Value.(*memoryEntry).key)
+		m.evictions++
+	}
+
+	entry := &memoryEntry{key: key}
+	m.entries[key] = m.lru.PushFront(entry)
+	return entry
+}
+
+func (m *Memory) sweep(interval time.Duration) {
+	defer close(m.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-m.stop:
+			return
+		case now := <-ticker.C:
+			m.removeExpired(now)
+		}
+	}
+}
+
+// removeExpired drops every entry that has expired by now. Entries are
+// scanned in batches so Allow is never blocked for long.
+func (m *Memory) removeExpired(now time.Time) {
+	const batch = 1024
+
+	m.mu.Lock()
+	element := m.lru.Back()
+	m.mu.Unlock()
+
+	for element != nil {
+		m.mu.Lock()
+		for i := 0; i < batch && element != nil; i++ {
+			previous := element.Prev()
+			entry := element.Value.(*memoryEntry)
+			// An element removed by eviction since the last batch has no
+			// list any more; Prev returns nil for it and ends the scan.
+			if !now.Before(entry.expiresAt) && m.entries[entry.key] == element {
+				m.lru.Remove(element)
+				delete(m.entries, entry.key)
+				m.expirations++
+			}
+			element = previous
+		}
+		m.mu.Unlock()
+	}
+}
+
+func (e *memoryEntry) fixedWindow(now time.Time, limit Limit) Result {
+	if e.windowStart.IsZero() || !now.Before(e.windowStart.Add(limit.Window)) {
+		e.windowStart = now
+		e.count = 0
+	}
+
+	reset := e.windowStart.Add(limit.Window).Sub(now)
+	if e.count >= limit.Max {
+		return Result{Limit: limit.Max, Reset: reset, RetryAfter: reset}
+	}
+
+	e.count++
+	e.expiresAt = e.windowStart.Add(limit.Window)
+	return Result{Allowed: true, Limit: limit.Max, Remaining: limit.Max - e.count, Reset: reset}
+}
+
+func (e *memoryEntry) slidingWindowLog(now time.Time, limit Limit) Result {
+	cutoff := now.Add(-limit.Window)
+	expired := 0
+	for expired < len(e.hits) && !e.hits[expired].After(cutoff) {
+		expired++
+	}
+	e.hits = e.hits[expired:]
+
+	if len(e.hits) >= limit.Max {
+		wait := e.hits[0].Add(limit.Window).Sub(now)
+		return Result{Limit: limit.Max, Reset: wait, RetryAfter: wait}
+	}
+
+	e.hits = append(e.hits, now)
+	e.expiresAt = now.Add(limit.Window)
+	return Result{
+		Allowed:   true,
+		Limit:     limit.Max,
+		Remaining: limit.Max - len(e.hits),
+		Reset:     e.hits[0].Add(limit.Window).Sub(now),
+	}
+}
+
+func (e *memoryEntry) slidingWindowCounter(now time.Time, limit Limit) Result {
+	start := now.Truncate(limit.Window)
+	if !e.windowStart.Equal(start) {
+		if e.windowStart.Equal(start.Add(-limit.Window)) {
+			e.previous = e.count
+		} else {
+			e.previous = 0
+		}
+		e.windowStart = start
+		e.count = 0
+	}
+
+	elapsed := now.Sub(start)
+	reset := limit.Window - elapsed
+	estimate := float64(e.previous)*float64(reset)/float64(limit.Window) + float64(e.count)
+	if estimate+1 > float64(limit.Max) {
+		wait := reset
+		if e.count+1 <= limit.Max && e.previous > 0 {
+			// The estimate falls as the previous window slides out; wait
+			// until it leaves room for one more request.
+			needed := time.Duration(float64(limit.Window) * (1 - float64(limit.Max-e.count-1)/float64(e.previous)))
+			wait = max(needed-elapsed, time.Millisecond)
+		}
+		return Result{Limit: limit.Max, Reset: reset, RetryAfter: wait}
+	}
+
+	e.count++
+	e.expiresAt = start.Add(2 * limit.Window)
+	return Result{
+		Allowed:   true,
+		Limit:     limit.Max,
+		Remaining: int(math.Floor(float64(limit.Max) - estimate - 1)),
+		Reset:     reset,
+	}
+}
+
+func (e *memoryEntry) tokenBucket(now time.Time, limit Limit) Result {
+	rate := float64(limit.Max) / float64(limit.Window)
+	if e.updatedAt.IsZero() {
+		e.tokens = float64(limit.Max)
+	} else {
+		e.tokens = math.Min(float64(limit.Max), e.tokens+float64(now.Sub(e.updatedAt))*rate)
+	}
+	e.updatedAt = now
+
+	result := Result{Limit: limit.Max}
+	if e.tokens >= 1 {
+		e.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = time.Duration(math.Ceil((1 - e.tokens) / rate))
+	}
+	result.Remaining = int(math.Floor(e.tokens))
+	result.Reset = time.Duration(math.Ceil((float64(limit.Max) - e.tokens) / rate))
+	e.expiresAt = now.Add(result.Reset)
+	return result
+}
@@ -0,0 +1,82 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go-fiber-boilerplate/config"
+)
+
+// Algorithm selects how a Limit counts requests.
+type Algorithm string
+
+const (
+	// FixedWindow counts requests in consecutive windows. Cheap, but allows
+	// up to twice Max across a window boundary.
+	FixedWindow Algorithm = "fixed_window"
+	// SlidingWindowLog remembers the time of every allowed request in the
+	// last Window. Exact, at the cost of storing up to Max timestamps.
+	SlidingWindowLog Algorithm = "sliding_window_log"
+	// SlidingWindowCounter weighs the previous window's count by how much of
+	// it still overlaps the sliding window. A close approximation of the log
+	// with two counters per key.
+	SlidingWindowCounter Algorithm = "sliding_window_counter"
+	// TokenBucket refills Max tokens evenly over Window and spends one per
+	// request, allowing bursts of up to Max.
+	TokenBucket Algorithm = "token_bucket"
+)
+
+// Limit allows Max requests per Window, counted with Algorithm.
+type Limit struct {
+	Algorithm Algorithm
+	Max       int
+	Window    time.Duration
+	// FailClosed rejects requests while the store is unavailable instead of
+	// letting them through unlimited. Use it for routes where an unlimited
+	// client is worse than an outage, such as credential checks.
+	FailClosed bool
+}
+
+// Result is the outcome of a request against a Limit.
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Reset is how long until the limit is back to its full quota for the
+	// fixed and sliding windows, or until the bucket is full again.
+	Reset time.Duration
+	// RetryAfter is how long a rejected client should wait before the next
+	// request can be allowed. It is zero for allowed requests.
+	RetryAfter time.Duration
+}
+
+// Store keeps the state of RateLimitMiddleware. Implementations must apply
+// each algorithm atomically per key; the Redis store also shares state
+// between replicas.
+type Store interface {
+	// Allow counts a request for key against limit. Rejected requests are not
+	// counted.
+	Allow(ctx context.Context, key string, limit Limit) (Result, error)
+	// Close releases the store's resources and stops its background work.
+	Close() error
+}
+
+// StatsReporter is implemented by stores that can report what they track.
+type StatsReporter interface {
+	Stats() Stats
+}
+
+// New returns the store selected by cfg.RateLimitStore.
+func New(cfg *config.Config) (Store, error) {
+	switch cfg.RateLimitStore {
+	case config.RateLimitStoreMemory:
+		return NewMemory(cfg.RateLimitMaxKeys, cfg.RateLimitSweepInterval), nil
+	case config.RateLimitStoreRedis:
+		return NewRedis(cfg.RedisURL)
+	default:
+		return nil, fmt.Errorf("unknown rate limit store %q", cfg.RateLimitStore)
+	}
+}
+
+var _ StatsReporter = (*Memory)(nil)
@@ -0,0 +1,184 @@
+package ratelimit
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Redis keeps rate limit state in Redis so limits survive restarts and are
+// shared by every replica. Each algorithm runs as a Lua script, which Redis
+// executes atomically, and uses the Redis clock so replicas agree on time.
+// Every key expires once it no longer affects the limit.
+type Redis struct {
+	client *redis.Client
+}
+
+// Every script takes the limit as ARGV[1] (max) and ARGV[2] (window in ms)
+// and returns {allowed, remaining, reset_ms, retry_after_ms}.
+const redisNow = `
+local time = redis.call("TIME")
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+local max = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+`
+
+var redisScripts = map[Algorithm]*redis.Script{
+	FixedWindow: redis.NewScript(redisNow + `
+local ttl = redis.call("PTTL", KEYS[1])
+if ttl < 0 then
+	redis.call("SET", KEYS[1], 0, "PX", window)
+	ttl = window
+end
+local count = tonumber(redis.call("GET", KEYS[1]))
+if count >= max then
+	return {0, 0, ttl, ttl}
+end
+count = redis.call("INCR", KEYS[1])
+return {1, max - count, ttl, 0}
+`),
+
+	// A sorted set of request timestamps; ARGV[3] makes each member unique.
+	SlidingWindowLog: redis.NewScript(redisNow + `
+redis.call("ZREMRANGEBYSCORE", KEYS[1], "-inf", now - window)
+local count = redis.call("ZCARD", KEYS[1])
+if count >= max then
+	local oldest = redis.call("ZRANGE", KEYS[1], 0, 0, "WITHSCORES")
+	local wait = tonumber(oldest[2]) + window - now
+	return {0, 0, wait, wait}
+end
+redis.call("ZADD", KEYS[1], now, ARGV[3])
+redis.call("PEXPIRE", KEYS[1], window)
+local oldest = redis.call("ZRANGE", KEYS[1], 0, 0, "WITHSCORES")
+return {1, max - count - 1, tonumber(oldest[2]) + window - now, 0}
+`),
+
+	SlidingWindowCounter: redis.NewScript(redisNow + `
+local start = now - (now % window)
+local state = redis.call("HMGET", KEYS[1], "start", "count", "previous")
+local stored = tonumber(state[1])
+local count = tonumber(state[2]) or 0
+local previous = tonumber(state[3]) or 0
+if stored ~= start then
+	if stored == start - window then
+		previous = count
+	else
+		previous = 0
+	end
+	count = 0
+end
+
+local elapsed = now - start
+local reset = window - elapsed
+local estimate = previous * reset / window + count
+local allowed = 0
+local wait = 0
+if estimate + 1 > max then
+	wait = reset
+	if count + 1 <= max and previous > 0 then
+		wait = math.max(math.ceil(window * (1 - (max - count - 1) / previous)) - elapsed, 1)
+	end
+else
+	allowed = 1
+	count = count + 1
+end
+
+redis.call("HSET", KEYS[1], "start", start, "count", count, "previous", previous)
+redis.call("PEXPIRE", KEYS[1], reset + window)
+if allowed == 0 then
+	return {0, 0, reset, wait}
+end
+return {1, math.floor(max - estimate - 1), reset, 0}
+`),
+
+	TokenBucket: redis.NewScript(redisNow + `
+local rate = max / window
+local state = redis.call("HMGET", KEYS[1], "tokens", "updated_at")
+local tokens = tonumber(state[1])
+if tokens == nil then
+	tokens = max
+else
+	tokens = math.min(max, tokens + (now - tonumber(state[2])) * rate)
+end
+
+local allowed = 0
+local wait = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+else
+	wait = math.ceil((1 - tokens) / rate)
+end
+
+local reset = math.ceil((max - tokens) / rate)
+redis.call("HSET", KEYS[1], "tokens", tostring(tokens), "updated_at", now)
+redis.call("PEXPIRE", KEYS[1], math.max(reset, 1))
+return {allowed, math.floor(tokens), reset, wait}
+`),
+}
+
+const redisKeyPrefix = "ratelimit:"
+
+func NewRedis(url string) (*Redis, error) {
+	opts, err := redis.ParseURL(url)
+	if err != nil {
+		return nil, fmt.Errorf("invalid REDIS_URL: %w", err)
+	}
+	client := redis.NewClient(opts)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to reach redis: %w", err)
+	}
+
+	return &Redis{client: client}, nil
+}
+
+func (r *Redis) Allow(ctx context.Context, key string, limit Limit) (Result, error) {
+	script, ok := redisScripts[limit.Algorithm]
+	if !ok {
+		return Result{}, fmt.Errorf("unknown rate limit algorithm %q", limit.Algorithm)
+	}
+
+	args := []interface{}{limit.Max, limit.Window.Milliseconds()}
+	if limit.Algorithm == SlidingWindowLog {
+		member, err := uniqueMember()
+		if err != nil {
+			return Result{}, err
+		}
+		args = append(args, member)
+	}
+
+	key = redisKeyPrefix + string(limit.Algorithm) + ":" + key
+	values, err := script.Run(ctx, r.client, []string{key}, args...).Int64Slice()
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to apply rate limit: %w", err)
+	}
+
+	return Result{
+		Allowed:    values[0] == 1,
+		Limit:      limit.Max,
+		Remaining:  int(values[1]),
+		Reset:      time.Duration(values[2]) * time.Millisecond,
+		RetryAfter: time.Duration(values[3]) * time.Millisecond,
+	}, nil
+}
+
+// Close closes the connection pool.
+func (r *Redis) Close() error {
+	return r.client.Close()
+}
+
+func uniqueMember() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate rate limit member: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
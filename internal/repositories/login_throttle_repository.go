@@ -0,0 +1,73 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"go-fiber-boilerplate/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// LoginThrottleRepository stores the failed login counters, keyed by the
+// hash of an email address.
+type LoginThrottleRepository interface {
+	Find(ctx context.Context, emailHash string) (*models.LoginThrottle, error)
+	// FindOrCreateForUpdate returns the throttle of emailHash, creating an
+	// empty one when there is none, and locks it until the transaction ends.
+	FindOrCreateForUpdate(ctx context.Context, emailHash string) (*models.LoginThrottle, error)
+	Save(ctx context.Context, throttle *models.LoginThrottle) error
+	Delete(ctx context.Context, emailHash string) error
+	// DeleteStale removes the throttles whose last failure is older than
+	// before and that are not locked at now, returning how many.
+	DeleteStale(ctx context.Context, before, now time.Time) (int64, error)
+}
+
+type gormLoginThrottleRepository struct {
+	db *gorm.DB
+}
+
+func NewLoginThrottleRepository(db *gorm.DB) LoginThrottleRepository {
+	return &gormLoginThrottleRepository{db: db}
+}
+
+func (r *gormLoginThrottleRepository) Find(ctx context.Context, emailHash string) (*models.LoginThrottle, error) {
+	var throttle models.LoginThrottle
+	if err := Conn(ctx, r.db).Where("email_hash = ?", emailHash).First(&throttle).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &throttle, nil
+}
+
+func (r *gormLoginThrottleRepository) FindOrCreateForUpdate(ctx context.Context, emailHash string) (*models.LoginThrottle, error) {
+	db := Conn(ctx, r.db)
+	if err := db.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&models.LoginThrottle{EmailHash: emailHash}).Error; err != nil {
+		return nil, err
+	}
+
+	var throttle models.LoginThrottle
+	if err := db.
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("email_hash = ?", emailHash).
+		First(&throttle).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &throttle, nil
+}
+
+func (r *gormLoginThrottleRepository) Save(ctx context.Context, throttle *models.LoginThrottle) error {
+	return Conn(ctx, r.db).Save(throttle).Error
+}
+
+func (r *gormLoginThrottleRepository) Delete(ctx context.Context, emailHash string) error {
+	return Conn(ctx, r.db).Where("email_hash = ?", emailHash).Delete(&models.LoginThrottle{}).Error
+}
+
+func (r *gormLoginThrottleRepository) DeleteStale(ctx context.Context, before, now time.Time) (int64, error) {
+	result := Conn(ctx, r.db).
+		Where("last_failed_at < ? AND (locked_until IS NULL OR locked_until < ?)", before, now).
+		Delete(&models.LoginThrottle{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,79 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"go-fiber-boilerplate/internal/models"
+	"go-fiber-boilerplate/internal/repositories"
+)
+
+// LoginThrottleRepository is an in-memory
+// repositories.LoginThrottleRepository.
+type LoginThrottleRepository struct {
+	store
+	throttles map[string]models.LoginThrottle
+}
+
+func NewLoginThrottleRepository() *LoginThrottleRepository {
+	return &LoginThrottleRepository{throttles: make(map[string]models.LoginThrottle)}
+}
+
+func (r *LoginThrottleRepository) Find(ctx context.Context, emailHash string) (*models.LoginThrottle, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	throttle, ok := r.throttles[emailHash]
+	if !ok {
+		return nil, repositories.ErrNotFound
+	}
+	return &throttle, nil
+}
+
+func (r *LoginThrottleRepository) FindOrCreateForUpdate(ctx context.Context, emailHash string) (*models.LoginThrottle, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	throttle, ok := r.throttles[emailHash]
+	if !ok {
+		throttle = models.LoginThrottle{ID: r.newID(), EmailHash: emailHash, CreatedAt: time.Now()}
+		r.throttles[emailHash] = throttle
+	}
+	return &throttle, nil
+}
+
+func (r *LoginThrottleRepository) Save(ctx context.Context, throttle *models.LoginThrottle) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if throttle.ID == 0 {
+		throttle.ID = r.newID()
+		throttle.CreatedAt = time.Now()
+	}
+	throttle.UpdatedAt = time.Now()
+	r.throttles[throttle.EmailHash] = *throttle
+	return nil
+}
+
+func (r *LoginThrottleRepository) Delete(ctx context.Context, emailHash string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.throttles, emailHash)
+	return nil
+}
+
+func (r *LoginThrottleRepository) DeleteStale(ctx context.Context, before, now time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var deleted int64
+	for emailHash, throttle := range r.throttles {
+		locked := throttle.LockedUntil != nil && !throttle.LockedUntil.Before(now)
+		if throttle.LastFailedAt.Before(before) && !locked {
+			delete(r.throttles, emailHash)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
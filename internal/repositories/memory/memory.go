@@ -0,0 +1,82 @@
+// Package memory provides in-memory fakes of the repositories for tests.
+// They keep every record in maps guarded by a mutex and return copies, so
+// callers see the same isolation as with a database. Transactions are not
+// rolled back: Transactor simply runs the function.
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"go-fiber-boilerplate/internal/repositories"
+)
+
+// Transactor runs transactions without isolation or rollback.
+type Transactor struct{}
+
+func (Transactor) Transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+// Repositories bundles fakes that share state, e.g. samples resolve their
+// owner from Users and Roles keeps the roles of a user on it.
+type Repositories struct {
+	Transactor         Transactor
+	Users              *UserRepository
+	Samples            *SampleRepository
+	ResetTokens        *ResetTokenRepository
+	RefreshTokens      *RefreshTokenRepository
+	RevokedTokens      *RevokedTokenRepository
+	VerificationTokens *VerificationTokenRepository
+	UnlockTokens       *UnlockTokenRepository
+	LoginThrottles     *LoginThrottleRepository
+	RecoveryCodes      *RecoveryCodeRepository
+	Roles              *RoleRepository
+	Uploads            *UploadRepository
+	PendingDeletions   *PendingDeletionRepository
+}
+
+func New() *Repositories {
+	users := NewUserRepository()
+	return &Repositories{
+		Users:              users,
+		Samples:            NewSampleRepository(users),
+		ResetTokens:        NewResetTokenRepository(),
+		RefreshTokens:      NewRefreshTokenRepository(),
+		RevokedTokens:      NewRevokedTokenRepository(),
+		VerificationTokens: NewVerificationTokenRepository(),
+		UnlockTokens:       NewUnlockTokenRepository(),
+		LoginThrottles:     NewLoginThrottleRepository(),
+		RecoveryCodes:      NewRecoveryCodeRepository(),
+		Roles:              NewRoleRepository(users),
+		Uploads:            NewUploadRepository(),
+		PendingDeletions:   NewPendingDeletionRepository(),
+	}
+}
+
+var (
+	_ repositories.Transactor                  = Transactor{}
+	_ repositories.UserRepository              = (*UserRepository)(nil)
+	_ repositories.SampleRepository            = (*SampleRepository)(nil)
+	_ repositories.ResetTokenRepository        = (*ResetTokenRepository)(nil)
+	_ repositories.RefreshTokenRepository      = (*RefreshTokenRepository)(nil)
+	_ repositories.RevokedTokenRepository      = (*RevokedTokenRepository)(nil)
+	_ repositories.VerificationTokenRepository = (*VerificationTokenRepository)(nil)
+	_ repositories.UnlockTokenRepository       = (*UnlockTokenRepository)(nil)
+	_ repositories.LoginThrottleRepository     = (*LoginThrottleRepository)(nil)
+	_ repositories.RecoveryCodeRepository      = (*RecoveryCodeRepository)(nil)
+	_ repositories.RoleRepository              = (*RoleRepository)(nil)
+	_ repositories.UploadRepository            = (*UploadRepository)(nil)
+	_ repositories.PendingDeletionRepository   = (*PendingDeletionRepository)(nil)
+)
+
+// store is the id sequence and lock shared by the fakes' tables.
+type store struct {
+	mu     sync.Mutex
+	nextID uint
+}
+
+func (s *store) newID() uint {
+	s.nextID++
+	return s.nextID
+}
@@ -0,0 +1,86 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"go-fiber-boilerplate/internal/models"
+)
+
+// PendingDeletionRepository is an in-memory
+// repositories.PendingDeletionRepository.
+type PendingDeletionRepository struct {
+	store
+	deletions map[string]models.PendingAssetDeletion
+}
+
+func NewPendingDeletionRepository() *PendingDeletionRepository {
+	return &PendingDeletionRepository{deletions: make(map[string]models.PendingAssetDeletion)}
+}
+
+func (r *PendingDeletionRepository) Queue(ctx context.Context, pending *models.PendingAssetDeletion) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	if existing, ok := r.deletions[pending.PublicID]; ok {
+		existing.LastError = pending.LastError
+		existing.UpdatedAt = now
+		r.deletions[pending.PublicID] = existing
+		return nil
+	}
+
+	pending.ID = r.newID()
+	pending.CreatedAt = now
+	pending.UpdatedAt = now
+	r.deletions[pending.PublicID] = *pending
+	return nil
+}
+
+func (r *PendingDeletionRepository) Due(ctx context.Context, now time.Time, limit int) ([]models.PendingAssetDeletion, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var due []models.PendingAssetDeletion
+	for _, pending := range r.deletions {
+		if !pending.NextAttemptAt.After(now) {
+			due = append(due, pending)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i].NextAttemptAt.Before(due[j].NextAttemptAt) })
+	return due[:min(limit, len(due))], nil
+}
+
+func (r *PendingDeletionRepository) Save(ctx context.Context, pending *models.PendingAssetDeletion) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	pending.UpdatedAt = time.Now()
+	r.deletions[pending.PublicID] = *pending
+	return nil
+}
+
+func (r *PendingDeletionRepository) Delete(ctx context.Context, publicID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.deletions, publicID)
+	return nil
+}
+
+func (r *PendingDeletionRepository) Count(ctx context.Context) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return int64(len(r.deletions)), nil
+}
+
+// Find returns the queued deletion of publicID, for assertions in tests.
+func (r *PendingDeletionRepository) Find(publicID string) (models.PendingAssetDeletion, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	pending, ok := r.deletions[publicID]
+	return pending, ok
+}
@@ -0,0 +1,60 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"go-fiber-boilerplate/internal/models"
+)
+
+// RecoveryCodeRepository is an in-memory repositories.RecoveryCodeRepository.
+type RecoveryCodeRepository struct {
+	store
+	codes map[uint]models.MFARecoveryCode
+}
+
+func NewRecoveryCodeRepository() *RecoveryCodeRepository {
+	return &RecoveryCodeRepository{codes: make(map[uint]models.MFARecoveryCode)}
+}
+
+func (r *RecoveryCodeRepository) Replace(ctx context.Context, userID uint, codeHashes []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.deleteForUser(userID)
+	for _, codeHash := range codeHashes {
+		id := r.newID()
+		r.codes[id] = models.MFARecoveryCode{ID: id, UserID: userID, CodeHash: codeHash, CreatedAt: time.Now()}
+	}
+	return nil
+}
+
+func (r *RecoveryCodeRepository) Consume(ctx context.Context, userID uint, codeHash string, at time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, code := range r.codes {
+		if code.UserID == userID && code.CodeHash == codeHash && code.UsedAt == nil {
+			code.UsedAt = &at
+			r.codes[id] = code
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *RecoveryCodeRepository) DeleteForUser(ctx context.Context, userID uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.deleteForUser(userID)
+	return nil
+}
+
+func (r *RecoveryCodeRepository) deleteForUser(userID uint) {
+	for id, code := range r.codes {
+		if code.UserID == userID {
+			delete(r.codes, id)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"go-fiber-boilerplate/internal/models"
+	"go-fiber-boilerplate/internal/repositories"
+)
+
+// RefreshTokenRepository is an in-memory repositories.RefreshTokenRepository.
+type RefreshTokenRepository struct {
+	store
+	tokens map[uint]models.RefreshToken
+}
+
+func NewRefreshTokenRepository() *RefreshTokenRepository {
+	return &RefreshTokenRepository{tokens: make(map[uint]models.RefreshToken)}
+}
+
+func (r *RefreshTokenRepository) Create(ctx context.Context, token *models.RefreshToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	token.ID = r.newID()
+	token.CreatedAt = time.Now()
+	r.tokens[token.ID] = *token
+	return nil
+}
+
+func (r *RefreshTokenRepository) FindByHash(ctx context.Context, tokenHash string) (*models.RefreshToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, token := range r.tokens {
+		if token.TokenHash == tokenHash {
+			return &token, nil
+		}
+	}
+	return nil, repositories.ErrNotFound
+}
+
+func (r *RefreshTokenRepository) FindByHashForUpdate(ctx context.Context, tokenHash string) (*models.RefreshToken, error) {
+	return r.FindByHash(ctx, tokenHash)
+}
+
+func (r *RefreshTokenRepository) MarkUsed(ctx context.Context, id uint, at time.Time) error {
+	r.update(func(token *models.RefreshToken) bool { return token.ID == id }, func(token *models.RefreshToken) {
+		token.UsedAt = &at
+	})
+	return nil
+}
+
+func (r *RefreshTokenRepository) RevokeFamily(ctx context.Context, familyID string, at time.Time) error {
+	r.revoke(func(token *models.RefreshToken) bool { return token.FamilyID == familyID }, at)
+	return nil
+}
+
+func (r *RefreshTokenRepository) RevokeAllForUser(ctx context.Context, userID uint, at time.Time) error {
+	r.revoke(func(token *models.RefreshToken) bool { return token.UserID == userID }, at)
+	return nil
+}
+
+func (r *RefreshTokenRepository) revoke(match func(*models.RefreshToken) bool, at time.Time) {
+	r.update(func(token *models.RefreshToken) bool {
+		return token.RevokedAt == nil && match(token)
+	}, func(token *models.RefreshToken) {
+		token.RevokedAt = &at
+	})
+}
+
+// update applies fn to every stored token matching match.
+func (r *RefreshTokenRepository) update(match func(*models.RefreshToken) bool, fn func(*models.RefreshToken)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, token := range r.tokens {
+		if match(&token) {
+			fn(&token)
+			r.tokens[id] = token
+		}
+	}
+}
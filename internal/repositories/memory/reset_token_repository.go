@@ -0,0 +1,58 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"go-fiber-boilerplate/internal/models"
+	"go-fiber-boilerplate/internal/repositories"
+)
+
+// ResetTokenRepository is an in-memory repositories.ResetTokenRepository.
+type ResetTokenRepository struct {
+	store
+	tokens map[uint]models.PasswordResetToken
+}
+
+func NewResetTokenRepository() *ResetTokenRepository {
+	return &ResetTokenRepository{tokens: make(map[uint]models.PasswordResetToken)}
+}
+
+func (r *ResetTokenRepository) Replace(ctx context.Context, token *models.PasswordResetToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, existing := range r.tokens {
+		if existing.UserID == token.UserID {
+			delete(r.tokens, id)
+		}
+	}
+
+	token.ID = r.newID()
+	token.CreatedAt = time.Now()
+	r.tokens[token.ID] = *token
+	return nil
+}
+
+func (r *ResetTokenRepository) FindByHashForUpdate(ctx context.Context, tokenHash string) (*models.PasswordResetToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, token := range r.tokens {
+		if token.TokenHash == tokenHash {
+			return &token, nil
+		}
+	}
+	return nil, repositories.ErrNotFound
+}
+
+func (r *ResetTokenRepository) MarkUsed(ctx context.Context, id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if token, ok := r.tokens[id]; ok {
+		token.Used = true
+		r.tokens[id] = token
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"go-fiber-boilerplate/internal/models"
+)
+
+// RevokedTokenRepository is an in-memory repositories.RevokedTokenRepository.
+type RevokedTokenRepository struct {
+	store
+	tokens map[string]models.RevokedToken
+}
+
+func NewRevokedTokenRepository() *RevokedTokenRepository {
+	return &RevokedTokenRepository{tokens: make(map[string]models.RevokedToken)}
+}
+
+func (r *RevokedTokenRepository) Add(ctx context.Context, token *models.RevokedToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.tokens[token.JTI]; exists {
+		return nil
+	}
+	token.ID = r.newID()
+	token.CreatedAt = time.Now()
+	r.tokens[token.JTI] = *token
+	return nil
+}
+
+func (r *RevokedTokenRepository) Exists(ctx context.Context, jti string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, exists := r.tokens[jti]
+	return exists, nil
+}
+
+func (r *RevokedTokenRepository) DeleteExpired(ctx context.Context, before time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for jti, token := range r.tokens {
+		if token.ExpiresAt.Before(before) {
+			delete(r.tokens, jti)
+		}
+	}
+	return nil
+}
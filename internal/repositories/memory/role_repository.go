@@ -0,0 +1,92 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"go-fiber-boilerplate/internal/models"
+)
+
+// RoleRepository is an in-memory repositories.RoleRepository holding the
+// default roles and permissions. The roles of a user are kept on the user in
+// the given user repository and resolved by name.
+type RoleRepository struct {
+	store
+	users *UserRepository
+	roles map[string]models.Role
+}
+
+func NewRoleRepository(users *UserRepository) *RoleRepository {
+	r := &RoleRepository{users: users, roles: make(map[string]models.Role)}
+
+	permissions := make(map[string]models.Permission, len(models.DefaultPermissions))
+	for name, description := range models.DefaultPermissions {
+		permissions[name] = models.Permission{ID: r.newID(), Name: name, Description: description, CreatedAt: time.Now()}
+	}
+	for name, granted := range models.DefaultRoles {
+		role := models.Role{ID: r.newID(), Name: name, CreatedAt: time.Now()}
+		for _, permission := range granted {
+			role.Permissions = append(role.Permissions, permissions[permission])
+		}
+		r.roles[name] = role
+	}
+	return r
+}
+
+func (r *RoleRepository) List(ctx context.Context) ([]models.Role, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	roles := make([]models.Role, 0, len(r.roles))
+	for _, role := range r.roles {
+		roles = append(roles, role)
+	}
+	sort.Slice(roles, func(i, j int) bool { return roles[i].Name < roles[j].Name })
+	return roles, nil
+}
+
+func (r *RoleRepository) FindByNames(ctx context.Context, names []string) ([]models.Role, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var roles []models.Role
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		if role, ok := r.roles[name]; ok && !seen[name] {
+			seen[name] = true
+			role.Permissions = nil
+			roles = append(roles, role)
+		}
+	}
+	return roles, nil
+}
+
+func (r *RoleRepository) UserPermissions(ctx context.Context, userID uint) ([]string, error) {
+	user, err := r.users.FindWithRoles(ctx, userID)
+	if err != nil {
+		// An unknown user has no roles, hence no permissions.
+		return nil, nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var names []string
+	seen := make(map[string]bool)
+	for _, granted := range user.Roles {
+		for _, permission := range r.roles[granted.Name].Permissions {
+			if !seen[permission.Name] {
+				seen[permission.Name] = true
+				names = append(names, permission.Name)
+			}
+		}
+	}
+	return names, nil
+}
+
+func (r *RoleRepository) SetUserRoles(ctx context.Context, userID uint, roles []models.Role) error {
+	return r.users.update(userID, func(user *models.User) {
+		user.Roles = append([]models.Role(nil), roles...)
+	})
+}
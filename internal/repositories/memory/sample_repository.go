@@ -0,0 +1,310 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"go-fiber-boilerplate/internal/models"
+	"go-fiber-boilerplate/internal/repositories"
+	"go-fiber-boilerplate/pkg/pagination"
+
+	"gorm.io/gorm"
+)
+
+// SampleRepository is an in-memory repositories.SampleRepository. Owners
+// are looked up in the given user repository.
+type SampleRepository struct {
+	store
+	users   *UserRepository
+	samples map[uint]models.Sample
+	images  map[uint]models.SampleImage
+}
+
+func NewSampleRepository(users *UserRepository) *SampleRepository {
+	return &SampleRepository{
+		users:   users,
+		samples: make(map[uint]models.Sample),
+		images:  make(map[uint]models.SampleImage),
+	}
+}
+
+func (r *SampleRepository) List(ctx context.Context, params pagination.Params) ([]models.Sample, int64, error) {
+	samples, total := r.page(func(models.Sample) bool { return true }, params)
+	for i := range samples {
+		r.withOwner(ctx, &samples[i])
+	}
+	return samples, total, nil
+}
+
+func (r *SampleRepository) ListByUser(ctx context.Context, userID uint, params pagination.Params) ([]models.Sample, int64, error) {
+	samples, total := r.page(func(sample models.Sample) bool { return sample.UserID == userID }, params)
+	return samples, total, nil
+}
+
+// page sorts the live samples matching keep like the allowed sortBy columns
+// of the GORM repository, newest first by default, and slices out a page.
+func (r *SampleRepository) page(keep func(models.Sample) bool, params pagination.Params) ([]models.Sample, int64) {
+	r.mu.Lock()
+	var matched []models.Sample
+	for _, sample := range r.samples {
+		if !sample.DeletedAt.Valid && keep(sample) {
+			matched = append(matched, sample)
+		}
+	}
+	r.mu.Unlock()
+
+	less := func(a, b models.Sample) bool {
+		switch params.SortBy {
+		case "id":
+			return a.ID < b.ID
+		case "title":
+			return a.Title < b.Title
+		case "updated_at":
+			return a.UpdatedAt.Before(b.UpdatedAt)
+		default:
+			return a.CreatedAt.Before(b.CreatedAt)
+		}
+	}
+	sort.SliceStable(matched, func(i, j int) bool {
+		if params.SortOrder == pagination.ASC {
+			return less(matched[i], matched[j])
+		}
+		return less(matched[j], matched[i])
+	})
+
+	total := int64(len(matched))
+	start := min(params.Offset(), len(matched))
+	end := min(start+params.PerPage, len(matched))
+	return matched[start:end], total
+}
+
+func (r *SampleRepository) FindByID(ctx context.Context, id uint) (*models.Sample, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	sample, ok := r.samples[id]
+	if !ok || sample.DeletedAt.Valid {
+		return nil, repositories.ErrNotFound
+	}
+	return &sample, nil
+}
+
+func (r *SampleRepository) FindWithDetails(ctx context.Context, id uint) (*models.Sample, error) {
+	sample, err := r.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	r.withOwner(ctx, sample)
+	if sample.Images, err = r.Images(ctx, id); err != nil {
+		return nil, err
+	}
+	return sample, nil
+}
+
+func (r *SampleRepository) TitleExists(ctx context.Context, title string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, sample := range r.samples {
+		if sample.Title == title && !sample.DeletedAt.Valid {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *SampleRepository) Create(ctx context.Context, sample *models.Sample) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	sample.ID = r.newID()
+	sample.CreatedAt = time.Now()
+	sample.UpdatedAt = sample.CreatedAt
+	r.samples[sample.ID] = stripped(*sample)
+	return nil
+}
+
+func (r *SampleRepository) Save(ctx context.Context, sample *models.Sample) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	sample.UpdatedAt = time.Now()
+	r.samples[sample.ID] = stripped(*sample)
+	return nil
+}
+
+func (r *SampleRepository) Delete(ctx context.Context, sample *models.Sample) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var publicIDs []string
+	for id, image := range r.images {
+		if image.SampleID == sample.ID {
+			publicIDs = append(publicIDs, image.PublicID)
+			delete(r.images, id)
+		}
+	}
+
+	if stored, ok := r.samples[sample.ID]; ok {
+		stored.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+		r.samples[sample.ID] = stored
+	}
+	return publicIDs, nil
+}
+
+func (r *SampleRepository) Lock(ctx context.Context, id uint) error {
+	_, err := r.FindByID(ctx, id)
+	return err
+}
+
+func (r *SampleRepository) Images(ctx context.Context, sampleID uint) ([]models.SampleImage, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.imagesLocked(sampleID), nil
+}
+
+func (r *SampleRepository) imagesLocked(sampleID uint) []models.SampleImage {
+	images := []models.SampleImage{}
+	for _, image := range r.images {
+		if image.SampleID == sampleID {
+			images = append(images, image)
+		}
+	}
+	sort.Slice(images, func(i, j int) bool {
+		if images[i].Position != images[j].Position {
+			return images[i].Position < images[j].Position
+		}
+		return images[i].ID < images[j].ID
+	})
+	return images
+}
+
+func (r *SampleRepository) FindImage(ctx context.Context, sampleID, imageID uint) (*models.SampleImage, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	image, ok := r.images[imageID]
+	if !ok || image.SampleID != sampleID {
+		return nil, repositories.ErrNotFound
+	}
+	return &image, nil
+}
+
+func (r *SampleRepository) CountImages(ctx context.Context, sampleID uint) (int64, error) {
+	images, err := r.Images(ctx, sampleID)
+	return int64(len(images)), err
+}
+
+func (r *SampleRepository) CreateImage(ctx context.Context, image *models.SampleImage) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	image.ID = r.newID()
+	image.CreatedAt = time.Now()
+	image.UpdatedAt = image.CreatedAt
+	r.images[image.ID] = *image
+	return nil
+}
+
+func (r *SampleRepository) SaveImage(ctx context.Context, image *models.SampleImage) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	image.UpdatedAt = time.Now()
+	r.images[image.ID] = *image
+	return nil
+}
+
+func (r *SampleRepository) DeleteImage(ctx context.Context, image *models.SampleImage) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.images, image.ID)
+	for id, other := range r.images {
+		if other.SampleID == image.SampleID && other.Position > image.Position {
+			other.Position--
+			r.images[id] = other
+		}
+	}
+	return nil
+}
+
+func (r *SampleRepository) SetImageOrder(ctx context.Context, sampleID uint, imageIDs []uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for position, id := range imageIDs {
+		if image, ok := r.images[id]; ok && image.SampleID == sampleID {
+			image.Position = position
+			r.images[id] = image
+		}
+	}
+	return nil
+}
+
+func (r *SampleRepository) SyncCover(ctx context.Context, sampleID uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	sample, ok := r.samples[sampleID]
+	if !ok {
+		return nil
+	}
+
+	var cover models.SampleImage
+	if images := r.imagesLocked(sampleID); len(images) > 0 {
+		cover = images[0]
+	}
+	sample.ImageURL = cover.URL
+	sample.ImagePublicID = cover.PublicID
+	r.samples[sampleID] = sample
+	return nil
+}
+
+func (r *SampleRepository) ReferencedAssets(ctx context.Context) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var publicIDs []string
+	for _, sample := range r.samples {
+		if !sample.DeletedAt.Valid && sample.ImagePublicID != "" {
+			publicIDs = append(publicIDs, sample.ImagePublicID)
+		}
+	}
+	for _, image := range r.images {
+		if sample, ok := r.samples[image.SampleID]; ok && !sample.DeletedAt.Valid {
+			publicIDs = append(publicIDs, image.PublicID)
+		}
+	}
+	return publicIDs, nil
+}
+
+func (r *SampleRepository) ForgetAsset(ctx context.Context, publicID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, sample := range r.samples {
+		if sample.DeletedAt.Valid && sample.ImagePublicID == publicID {
+			sample.ImageURL = ""
+			sample.ImagePublicID = ""
+			r.samples[id] = sample
+		}
+	}
+	return nil
+}
+
+func (r *SampleRepository) withOwner(ctx context.Context, sample *models.Sample) {
+	if owner, err := r.users.FindByID(ctx, sample.UserID); err == nil {
+		sample.User = *owner
+	}
+}
+
+// stripped drops the associations, which are loaded on read rather than
+// stored with the sample.
+func stripped(sample models.Sample) models.Sample {
+	sample.User = models.User{}
+	sample.Images = nil
+	return sample
+}
@@ -0,0 +1,69 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"go-fiber-boilerplate/internal/models"
+	"go-fiber-boilerplate/internal/repositories"
+)
+
+// UnlockTokenRepository is an in-memory repositories.UnlockTokenRepository.
+type UnlockTokenRepository struct {
+	store
+	tokens map[uint]models.AccountUnlockToken
+}
+
+func NewUnlockTokenRepository() *UnlockTokenRepository {
+	return &UnlockTokenRepository{tokens: make(map[uint]models.AccountUnlockToken)}
+}
+
+func (r *UnlockTokenRepository) Replace(ctx context.Context, token *models.AccountUnlockToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.deleteForUser(token.UserID)
+	token.ID = r.newID()
+	token.CreatedAt = time.Now()
+	r.tokens[token.ID] = *token
+	return nil
+}
+
+func (r *UnlockTokenRepository) FindByHashForUpdate(ctx context.Context, tokenHash string) (*models.AccountUnlockToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, token := range r.tokens {
+		if token.TokenHash == tokenHash {
+			return &token, nil
+		}
+	}
+	return nil, repositories.ErrNotFound
+}
+
+func (r *UnlockTokenRepository) MarkUsed(ctx context.Context, id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if token, ok := r.tokens[id]; ok {
+		token.Used = true
+		r.tokens[id] = token
+	}
+	return nil
+}
+
+func (r *UnlockTokenRepository) DeleteForUser(ctx context.Context, userID uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.deleteForUser(userID)
+	return nil
+}
+
+func (r *UnlockTokenRepository) deleteForUser(userID uint) {
+	for id, token := range r.tokens {
+		if token.UserID == userID {
+			delete(r.tokens, id)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"go-fiber-boilerplate/internal/models"
+	"go-fiber-boilerplate/internal/repositories"
+)
+
+// UploadRepository is an in-memory repositories.UploadRepository.
+type UploadRepository struct {
+	store
+	uploads map[string]models.ResumableUpload
+}
+
+func NewUploadRepository() *UploadRepository {
+	return &UploadRepository{uploads: make(map[string]models.ResumableUpload)}
+}
+
+func (r *UploadRepository) Create(ctx context.Context, upload *models.ResumableUpload) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	upload.CreatedAt = time.Now()
+	upload.UpdatedAt = upload.CreatedAt
+	r.uploads[upload.ID] = *upload
+	return nil
+}
+
+func (r *UploadRepository) FindActive(ctx context.Context, id string, userID uint, now time.Time) (*models.ResumableUpload, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	upload, ok := r.uploads[id]
+	if !ok || upload.UserID != userID || !upload.ExpiresAt.After(now) {
+		return nil, repositories.ErrNotFound
+	}
+	return &upload, nil
+}
+
+func (r *UploadRepository) Advance(ctx context.Context, id string, from, to int64, completedAt *time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	upload, ok := r.uploads[id]
+	if !ok || upload.Offset != from {
+		return false, nil
+	}
+	upload.Offset = to
+	if completedAt != nil {
+		upload.CompletedAt = completedAt
+	}
+	upload.UpdatedAt = time.Now()
+	r.uploads[id] = upload
+	return true, nil
+}
+
+func (r *UploadRepository) ExpiredIDs(ctx context.Context, now time.Time) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var ids []string
+	for id, upload := range r.uploads {
+		if !upload.ExpiresAt.After(now) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+func (r *UploadRepository) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.uploads, id)
+	return nil
+}
@@ -0,0 +1,252 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"go-fiber-boilerplate/internal/models"
+	"go-fiber-boilerplate/internal/repositories"
+	"go-fiber-boilerplate/pkg/pagination"
+
+	"gorm.io/gorm"
+)
+
+// UserRepository is an in-memory repositories.UserRepository. Roles passed
+// to Create are recorded by name only.
+type UserRepository struct {
+	store
+	users map[uint]models.User
+}
+
+func NewUserRepository() *UserRepository {
+	return &UserRepository{users: make(map[uint]models.User)}
+}
+
+// List filters like the GORM repository and sorts by its sortBy columns,
+// newest first by default.
+func (r *UserRepository) List(ctx context.Context, params pagination.Params) ([]models.User, int64, error) {
+	deleted, _ := params.Filter("deleted")
+	active, filterActive := params.BoolFilter("is_active")
+	verified, filterVerified := params.BoolFilter("verified")
+	role, filterRole := params.Filter("role")
+	search := strings.ToLower(params.Search)
+
+	r.mu.Lock()
+	var matched []models.User
+	for _, user := range r.users {
+		switch {
+		case user.DeletedAt.Valid && deleted != "include" && deleted != "only",
+			!user.DeletedAt.Valid && deleted == "only",
+			filterActive && user.IsActive != active,
+			filterVerified && (user.EmailVerifiedAt != nil) != verified,
+			filterRole && !hasRole(user, role),
+			search != "" && !strings.Contains(strings.ToLower(user.Email), search) &&
+				!strings.Contains(strings.ToLower(user.FirstName), search) &&
+				!strings.Contains(strings.ToLower(user.LastName), search):
+			continue
+		}
+		matched = append(matched, user)
+	}
+	r.mu.Unlock()
+
+	less := func(a, b models.User) bool {
+		switch params.SortBy {
+		case "id":
+			return a.ID < b.ID
+		case "email":
+			return a.Email < b.Email
+		case "first_name":
+			return a.FirstName < b.FirstName
+		case "last_name":
+			return a.LastName < b.LastName
+		case "updated_at":
+			return a.UpdatedAt.Before(b.UpdatedAt)
+		default:
+			return a.CreatedAt.Before(b.CreatedAt)
+		}
+	}
+	sort.SliceStable(matched, func(i, j int) bool {
+		if params.SortOrder == pagination.ASC {
+			return less(matched[i], matched[j])
+		}
+		return less(matched[j], matched[i])
+	})
+
+	total := int64(len(matched))
+	start := min(params.Offset(), len(matched))
+	end := min(start+params.PerPage, len(matched))
+	return matched[start:end], total, nil
+}
+
+func hasRole(user models.User, name string) bool {
+	for _, role := range user.Roles {
+		if role.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *UserRepository) FindByID(ctx context.Context, id uint) (*models.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, ok := r.users[id]
+	if !ok || user.DeletedAt.Valid {
+		return nil, repositories.ErrNotFound
+	}
+	return &user, nil
+}
+
+func (r *UserRepository) FindByIDForUpdate(ctx context.Context, id uint) (*models.User, error) {
+	return r.FindByID(ctx, id)
+}
+
+func (r *UserRepository) FindWithRoles(ctx context.Context, id uint) (*models.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, ok := r.users[id]
+	if !ok {
+		return nil, repositories.ErrNotFound
+	}
+	return &user, nil
+}
+
+func (r *UserRepository) FindByEmail(ctx context.Context, email string) (*models.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, user := range r.users {
+		if user.Email == email && !user.DeletedAt.Valid {
+			return &user, nil
+		}
+	}
+	return nil, repositories.ErrNotFound
+}
+
+func (r *UserRepository) EmailExists(ctx context.Context, email string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, user := range r.users {
+		if user.Email == email {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *UserRepository) Create(ctx context.Context, user *models.User, roles ...string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existing := range r.users {
+		if existing.Email == user.Email {
+			return errors.New("duplicate email")
+		}
+	}
+
+	user.ID = r.newID()
+	user.CreatedAt = time.Now()
+	user.UpdatedAt = user.CreatedAt
+	for _, role := range roles {
+		user.Roles = append(user.Roles, models.Role{Name: role})
+	}
+	r.users[user.ID] = *user
+	return nil
+}
+
+func (r *UserRepository) SetActive(ctx context.Context, id uint, active bool) error {
+	return r.update(id, func(user *models.User) {
+		user.IsActive = active
+	})
+}
+
+func (r *UserRepository) Delete(ctx context.Context, id uint) error {
+	return r.update(id, func(user *models.User) {
+		user.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+	})
+}
+
+func (r *UserRepository) Restore(ctx context.Context, id uint) error {
+	return r.update(id, func(user *models.User) {
+		user.DeletedAt = gorm.DeletedAt{}
+	})
+}
+
+func (r *UserRepository) UpdatePassword(ctx context.Context, id uint, passwordHash string) error {
+	return r.update(id, func(user *models.User) {
+		user.Password = passwordHash
+	})
+}
+
+func (r *UserRepository) MarkEmailVerified(ctx context.Context, id uint, at time.Time) error {
+	return r.update(id, func(user *models.User) {
+		user.EmailVerifiedAt = &at
+	})
+}
+
+func (r *UserRepository) IncrementTokenVersion(ctx context.Context, id uint) error {
+	return r.update(id, func(user *models.User) {
+		user.TokenVersion++
+	})
+}
+
+func (r *UserRepository) SetTOTPSecret(ctx context.Context, id uint, secret string) error {
+	return r.update(id, func(user *models.User) {
+		user.TOTPSecret = secret
+		user.TOTPLastStep = 0
+	})
+}
+
+func (r *UserRepository) EnableTOTP(ctx context.Context, id uint, step int64) error {
+	return r.update(id, func(user *models.User) {
+		user.TOTPEnabled = true
+		user.TOTPLastStep = step
+	})
+}
+
+func (r *UserRepository) DisableTOTP(ctx context.Context, id uint) error {
+	return r.update(id, func(user *models.User) {
+		user.TOTPSecret = ""
+		user.TOTPEnabled = false
+		user.TOTPLastStep = 0
+	})
+}
+
+func (r *UserRepository) SetTOTPLastStep(ctx context.Context, id uint, step int64) error {
+	return r.update(id, func(user *models.User) {
+		user.TOTPLastStep = step
+	})
+}
+
+// Put stores user as is, for seeding tests.
+func (r *UserRepository) Put(user models.User) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if user.ID == 0 {
+		user.ID = r.newID()
+	} else if user.ID > r.nextID {
+		r.nextID = user.ID
+	}
+	r.users[user.ID] = user
+}
+
+// update applies fn to a stored user. Like an UPDATE matching no rows, a
+// missing user is not an error.
+func (r *UserRepository) update(id uint, fn func(user *models.User)) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if user, ok := r.users[id]; ok {
+		fn(&user)
+		user.UpdatedAt = time.Now()
+		r.users[id] = user
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"go-fiber-boilerplate/internal/models"
+	"go-fiber-boilerplate/internal/repositories"
+)
+
+// VerificationTokenRepository is an in-memory
+// repositories.VerificationTokenRepository.
+type VerificationTokenRepository struct {
+	store
+	tokens map[uint]models.EmailVerificationToken
+}
+
+func NewVerificationTokenRepository() *VerificationTokenRepository {
+	return &VerificationTokenRepository{tokens: make(map[uint]models.EmailVerificationToken)}
+}
+
+func (r *VerificationTokenRepository) Replace(ctx context.Context, token *models.EmailVerificationToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, existing := range r.tokens {
+		if existing.UserID == token.UserID {
+			delete(r.tokens, id)
+		}
+	}
+
+	token.ID = r.newID()
+	token.CreatedAt = time.Now()
+	r.tokens[token.ID] = *token
+	return nil
+}
+
+func (r *VerificationTokenRepository) FindByHashForUpdate(ctx context.Context, tokenHash string) (*models.EmailVerificationToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, token := range r.tokens {
+		if token.TokenHash == tokenHash {
+			return &token, nil
+		}
+	}
+	return nil, repositories.ErrNotFound
+}
+
+func (r *VerificationTokenRepository) MarkUsed(ctx context.Context, id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if token, ok := r.tokens[id]; ok {
+		token.Used = true
+		r.tokens[id] = token
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"go-fiber-boilerplate/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PendingDeletionRepository stores the asset deletions that failed and are
+// retried by the reconciliation job.
+type PendingDeletionRepository interface {
+	// Queue records a failed deletion. Queueing an asset that is already
+	// queued only updates its last error, keeping its schedule.
+	Queue(ctx context.Context, pending *models.PendingAssetDeletion) error
+	// Due returns up to limit deletions whose next attempt is at or before
+	// now, the most overdue first.
+	Due(ctx context.Context, now time.Time, limit int) ([]models.PendingAssetDeletion, error)
+	Save(ctx context.Context, pending *models.PendingAssetDeletion) error
+	Delete(ctx context.Context, publicID string) error
+	Count(ctx context.Context) (int64, error)
+}
+
+type gormPendingDeletionRepository struct {
+	db *gorm.DB
+}
+
+func NewPendingDeletionRepository(db *gorm.DB) PendingDeletionRepository {
+	return &gormPendingDeletionRepository{db: db}
+}
+
+func (r *gormPendingDeletionRepository) Queue(ctx context.Context, pending *models.PendingAssetDeletion) error {
+	return Conn(ctx, r.db).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "public_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"last_error", "updated_at"}),
+	}).Create(pending).Error
+}
+
+func (r *gormPendingDeletionRepository) Due(ctx context.Context, now time.Time, limit int) ([]models.PendingAssetDeletion, error) {
+	var due []models.PendingAssetDeletion
+	if err := Conn(ctx, r.db).
+		Where("next_attempt_at <= ?", now).
+		Order("next_attempt_at ASC").
+		Limit(limit).
+		Find(&due).Error; err != nil {
+		return nil, err
+	}
+	return due, nil
+}
+
+func (r *gormPendingDeletionRepository) Save(ctx context.Context, pending *models.PendingAssetDeletion) error {
+	return Conn(ctx, r.db).Save(pending).Error
+}
+
+func (r *gormPendingDeletionRepository) Delete(ctx context.Context, publicID string) error {
+	return Conn(ctx, r.db).Where("public_id = ?", publicID).Delete(&models.PendingAssetDeletion{}).Error
+}
+
+func (r *gormPendingDeletionRepository) Count(ctx context.Context) (int64, error) {
+	var count int64
+	err := Conn(ctx, r.db).Model(&models.PendingAssetDeletion{}).Count(&count).Error
+	return count, err
+}
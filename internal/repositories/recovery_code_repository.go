@@ -0,0 +1,56 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"go-fiber-boilerplate/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// RecoveryCodeRepository stores the hashes of MFA recovery codes.
+type RecoveryCodeRepository interface {
+	// Replace makes codeHashes the user's only recovery codes.
+	Replace(ctx context.Context, userID uint, codeHashes []string) error
+	// Consume marks an unused code of the user as used and reports whether
+	// there was one.
+	Consume(ctx context.Context, userID uint, codeHash string, at time.Time) (bool, error)
+	DeleteForUser(ctx context.Context, userID uint) error
+}
+
+type gormRecoveryCodeRepository struct {
+	db *gorm.DB
+}
+
+func NewRecoveryCodeRepository(db *gorm.DB) RecoveryCodeRepository {
+	return &gormRecoveryCodeRepository{db: db}
+}
+
+func (r *gormRecoveryCodeRepository) Replace(ctx context.Context, userID uint, codeHashes []string) error {
+	return Conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&models.MFARecoveryCode{}).Error; err != nil {
+			return err
+		}
+		if len(codeHashes) == 0 {
+			return nil
+		}
+
+		records := make([]models.MFARecoveryCode, len(codeHashes))
+		for i, codeHash := range codeHashes {
+			records[i] = models.MFARecoveryCode{UserID: userID, CodeHash: codeHash}
+		}
+		return tx.Create(&records).Error
+	})
+}
+
+func (r *gormRecoveryCodeRepository) Consume(ctx context.Context, userID uint, codeHash string, at time.Time) (bool, error) {
+	result := Conn(ctx, r.db).Model(&models.MFARecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", at)
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *gormRecoveryCodeRepository) DeleteForUser(ctx context.Context, userID uint) error {
+	return Conn(ctx, r.db).Where("user_id = ?", userID).Delete(&models.MFARecoveryCode{}).Error
+}
@@ -0,0 +1,71 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"go-fiber-boilerplate/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RefreshTokenRepository stores the hashes of refresh tokens.
+type RefreshTokenRepository interface {
+	Create(ctx context.Context, token *models.RefreshToken) error
+	FindByHash(ctx context.Context, tokenHash string) (*models.RefreshToken, error)
+	// FindByHashForUpdate also locks the token until the transaction ends.
+	FindByHashForUpdate(ctx context.Context, tokenHash string) (*models.RefreshToken, error)
+	MarkUsed(ctx context.Context, id uint, at time.Time) error
+	// RevokeFamily revokes every token rotated from the same login.
+	RevokeFamily(ctx context.Context, familyID string, at time.Time) error
+	// RevokeAllForUser revokes every refresh token of the user.
+	RevokeAllForUser(ctx context.Context, userID uint, at time.Time) error
+}
+
+type gormRefreshTokenRepository struct {
+	db *gorm.DB
+}
+
+func NewRefreshTokenRepository(db *gorm.DB) RefreshTokenRepository {
+	return &gormRefreshTokenRepository{db: db}
+}
+
+func (r *gormRefreshTokenRepository) Create(ctx context.Context, token *models.RefreshToken) error {
+	return Conn(ctx, r.db).Create(token).Error
+}
+
+func (r *gormRefreshTokenRepository) FindByHash(ctx context.Context, tokenHash string) (*models.RefreshToken, error) {
+	var token models.RefreshToken
+	if err := Conn(ctx, r.db).Where("token_hash = ?", tokenHash).First(&token).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &token, nil
+}
+
+func (r *gormRefreshTokenRepository) FindByHashForUpdate(ctx context.Context, tokenHash string) (*models.RefreshToken, error) {
+	var token models.RefreshToken
+	if err := Conn(ctx, r.db).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("token_hash = ?", tokenHash).
+		First(&token).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &token, nil
+}
+
+func (r *gormRefreshTokenRepository) MarkUsed(ctx context.Context, id uint, at time.Time) error {
+	return Conn(ctx, r.db).Model(&models.RefreshToken{}).Where("id = ?", id).Update("used_at", at).Error
+}
+
+func (r *gormRefreshTokenRepository) RevokeFamily(ctx context.Context, familyID string, at time.Time) error {
+	return Conn(ctx, r.db).Model(&models.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", at).Error
+}
+
+func (r *gormRefreshTokenRepository) RevokeAllForUser(ctx context.Context, userID uint, at time.Time) error {
+	return Conn(ctx, r.db).Model(&models.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", at).Error
+}
@@ -0,0 +1,86 @@
+// Package repositories hides persistence behind interfaces so services can
+// be built on GORM in production and on the in-memory fakes of package
+// memory in tests.
+//
+// Transactions travel in the context: Transactor.Transaction passes fn a
+// context carrying the transaction, and every repository method called with
+// that context joins it by taking its connection from Conn.
+//
+// Reads that tolerate replication lag opt in to read replicas by marking
+// their context with ReadOnly; everything else stays on the primary.
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// ErrNotFound is returned when the requested record does not exist.
+var ErrNotFound = errors.New("record not found")
+
+// Transactor runs fn in a transaction that is committed when fn returns nil
+// and rolled back otherwise.
+type Transactor interface {
+	Transaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// Reader supplies the connection for reads that tolerate replication lag,
+// a read replica or the primary. database.Replicas implements it.
+type Reader interface {
+	Reader() *gorm.DB
+}
+
+type (
+	txKey       struct{}
+	readOnlyKey struct{}
+)
+
+// ReadOnly marks ctx for reads that may be served by a read replica: lists
+// and details shown to clients, not lookups that precede a write or read
+// back what the request has just written.
+func ReadOnly(ctx context.Context) context.Context {
+	return context.WithValue(ctx, readOnlyKey{}, true)
+}
+
+// Conn returns the transaction carried by ctx, or db when there is none.
+func Conn(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if tx, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return tx
+	}
+	return db.WithContext(ctx)
+}
+
+// ReadConn is Conn for reads: outside a transaction, a ctx marked ReadOnly
+// gets a connection from reader.
+func ReadConn(ctx context.Context, db *gorm.DB, reader Reader) *gorm.DB {
+	if _, inTx := ctx.Value(txKey{}).(*gorm.DB); !inTx && reader != nil {
+		if readOnly, _ := ctx.Value(readOnlyKey{}).(bool); readOnly {
+			return reader.Reader().WithContext(ctx)
+		}
+	}
+	return Conn(ctx, db)
+}
+
+type gormTransactor struct {
+	db *gorm.DB
+}
+
+func NewTransactor(db *gorm.DB) Transactor {
+	return &gormTransactor{db: db}
+}
+
+func (t *gormTransactor) Transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return Conn(ctx, t.db).Transaction(func(tx *gorm.DB) error {
+		return fn(context.WithValue(ctx, txKey{}, tx))
+	})
+}
+
+// notFound maps GORM's missing record error to ErrNotFound.
+func notFound(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrNotFound
+	}
+	return err
+}
@@ -0,0 +1,51 @@
+package repositories
+
+import (
+	"context"
+
+	"go-fiber-boilerplate/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ResetTokenRepository stores the hashes of password reset tokens.
+type ResetTokenRepository interface {
+	// Replace stores token as the only pending reset token of its user.
+	Replace(ctx context.Context, token *models.PasswordResetToken) error
+	// FindByHashForUpdate also locks the token until the transaction ends.
+	FindByHashForUpdate(ctx context.Context, tokenHash string) (*models.PasswordResetToken, error)
+	MarkUsed(ctx context.Context, id uint) error
+}
+
+type gormResetTokenRepository struct {
+	db *gorm.DB
+}
+
+func NewResetTokenRepository(db *gorm.DB) ResetTokenRepository {
+	return &gormResetTokenRepository{db: db}
+}
+
+func (r *gormResetTokenRepository) Replace(ctx context.Context, token *models.PasswordResetToken) error {
+	return Conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", token.UserID).Delete(&models.PasswordResetToken{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(token).Error
+	})
+}
+
+func (r *gormResetTokenRepository) FindByHashForUpdate(ctx context.Context, tokenHash string) (*models.PasswordResetToken, error) {
+	var token models.PasswordResetToken
+	if err := Conn(ctx, r.db).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("token_hash = ?", tokenHash).
+		First(&token).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &token, nil
+}
+
+func (r *gormResetTokenRepository) MarkUsed(ctx context.Context, id uint) error {
+	return Conn(ctx, r.db).Model(&models.PasswordResetToken{}).Where("id = ?", id).Update("used", true).Error
+}
@@ -0,0 +1,46 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"go-fiber-boilerplate/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RevokedTokenRepository stores the IDs of access tokens logged out before
+// they expired.
+type RevokedTokenRepository interface {
+	// Add records the token; adding a jti again is not an error.
+	Add(ctx context.Context, token *models.RevokedToken) error
+	Exists(ctx context.Context, jti string) (bool, error)
+	// DeleteExpired forgets tokens that expired before the given time, as
+	// they are rejected anyway.
+	DeleteExpired(ctx context.Context, before time.Time) error
+}
+
+type gormRevokedTokenRepository struct {
+	db *gorm.DB
+}
+
+func NewRevokedTokenRepository(db *gorm.DB) RevokedTokenRepository {
+	return &gormRevokedTokenRepository{db: db}
+}
+
+func (r *gormRevokedTokenRepository) Add(ctx context.Context, token *models.RevokedToken) error {
+	return Conn(ctx, r.db).Clauses(clause.OnConflict{DoNothing: true}).Create(token).Error
+}
+
+func (r *gormRevokedTokenRepository) Exists(ctx context.Context, jti string) (bool, error) {
+	var count int64
+	if err := Conn(ctx, r.db).Model(&models.RevokedToken{}).Where("jti = ?", jti).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *gormRevokedTokenRepository) DeleteExpired(ctx context.Context, before time.Time) error {
+	return Conn(ctx, r.db).Where("expires_at < ?", before).Delete(&models.RevokedToken{}).Error
+}
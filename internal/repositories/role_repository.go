@@ -0,0 +1,66 @@
+package repositories
+
+import (
+	"context"
+
+	"go-fiber-boilerplate/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// RoleRepository stores the roles, their permissions and the roles granted
+// to each user.
+type RoleRepository interface {
+	// List returns every role with its permissions, ordered by name.
+	List(ctx context.Context) ([]models.Role, error)
+	// FindByNames returns the roles with the given names; unknown names are
+	// skipped.
+	FindByNames(ctx context.Context, names []string) ([]models.Role, error)
+	// UserPermissions returns the distinct names of the permissions granted
+	// to the user through all of their roles.
+	UserPermissions(ctx context.Context, userID uint) ([]string, error)
+	// SetUserRoles replaces the roles of the user.
+	SetUserRoles(ctx context.Context, userID uint, roles []models.Role) error
+}
+
+type gormRoleRepository struct {
+	db *gorm.DB
+}
+
+func NewRoleRepository(db *gorm.DB) RoleRepository {
+	return &gormRoleRepository{db: db}
+}
+
+func (r *gormRoleRepository) List(ctx context.Context) ([]models.Role, error) {
+	var roles []models.Role
+	if err := Conn(ctx, r.db).Preload("Permissions").Order("name").Find(&roles).Error; err != nil {
+		return nil, err
+	}
+	return roles, nil
+}
+
+func (r *gormRoleRepository) FindByNames(ctx context.Context, names []string) ([]models.Role, error) {
+	var roles []models.Role
+	if err := Conn(ctx, r.db).Where("name IN ?", names).Find(&roles).Error; err != nil {
+		return nil, err
+	}
+	return roles, nil
+}
+
+func (r *gormRoleRepository) UserPermissions(ctx context.Context, userID uint) ([]string, error) {
+	var names []string
+	if err := Conn(ctx, r.db).
+		Table("permissions").
+		Joins("JOIN role_permissions ON role_permissions.permission_id = permissions.id").
+		Joins("JOIN user_roles ON user_roles.role_id = role_permissions.role_id").
+		Where("user_roles.user_id = ?", userID).
+		Distinct().
+		Pluck("permissions.name", &names).Error; err != nil {
+		return nil, err
+	}
+	return names, nil
+}
+
+func (r *gormRoleRepository) SetUserRoles(ctx context.Context, userID uint, roles []models.Role) error {
+	return Conn(ctx, r.db).Model(&models.User{ID: userID}).Association("Roles").Replace(roles)
+}
@@ -0,0 +1,256 @@
+package repositories
+
+import (
+	"context"
+
+	"go-fiber-boilerplate/internal/models"
+	"go-fiber-boilerplate/pkg/pagination"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SampleRepository stores samples together with their image galleries.
+// Lookups skip soft-deleted samples and are served by a read replica when
+// the context is marked ReadOnly.
+type SampleRepository interface {
+	// List returns a page of samples with their owner and the total count.
+	List(ctx context.Context, params pagination.Params) ([]models.Sample, int64, error)
+	// ListByUser returns a page of the user's samples and their total count.
+	ListByUser(ctx context.Context, userID uint, params pagination.Params) ([]models.Sample, int64, error)
+	FindByID(ctx context.Context, id uint) (*models.Sample, error)
+	// FindWithDetails also loads the owner and the ordered gallery.
+	FindWithDetails(ctx context.Context, id uint) (*models.Sample, error)
+	TitleExists(ctx context.Context, title string) (bool, error)
+	Create(ctx context.Context, sample *models.Sample) error
+	Save(ctx context.Context, sample *models.Sample) error
+	// Delete soft-deletes the sample, removes its gallery and returns the
+	// public IDs of the removed images.
+	Delete(ctx context.Context, sample *models.Sample) ([]string, error)
+
+	// Lock serialises gallery changes for the sample until the transaction
+	// ends, so positions stay contiguous under concurrent requests.
+	Lock(ctx context.Context, id uint) error
+	// Images returns the gallery ordered by position.
+	Images(ctx context.Context, sampleID uint) ([]models.SampleImage, error)
+	FindImage(ctx context.Context, sampleID, imageID uint) (*models.SampleImage, error)
+	CountImages(ctx context.Context, sampleID uint) (int64, error)
+	CreateImage(ctx context.Context, image *models.SampleImage) error
+	SaveImage(ctx context.Context, image *models.SampleImage) error
+	// DeleteImage removes the image and closes the gap it leaves.
+	DeleteImage(ctx context.Context, image *models.SampleImage) error
+	// SetImageOrder gives the listed images positions 0 to n-1.
+	SetImageOrder(ctx context.Context, sampleID uint, imageIDs []uint) error
+	// SyncCover mirrors the first gallery image into Sample.ImageURL and
+	// Sample.ImagePublicID, which list responses and older clients rely on.
+	SyncCover(ctx context.Context, sampleID uint) error
+
+	// ReferencedAssets returns the public IDs used by samples that are not
+	// deleted, either as the cover or as a gallery image.
+	ReferencedAssets(ctx context.Context) ([]string, error)
+	// ForgetAsset clears the cover of soft-deleted samples that still
+	// mention publicID once the asset itself is gone.
+	ForgetAsset(ctx context.Context, publicID string) error
+}
+
+var sampleSortableColumns = map[string]string{
+	"created_at": "created_at",
+	"updated_at": "updated_at",
+	"title":      "title",
+	"id":         "id",
+}
+
+type gormSampleRepository struct {
+	db     *gorm.DB
+	reader Reader
+}
+
+// NewSampleRepository builds the repository on db. reader may be nil, in
+// which case ReadOnly reads also go to db.
+func NewSampleRepository(db *gorm.DB, reader Reader) SampleRepository {
+	return &gormSampleRepository{db: db, reader: reader}
+}
+
+func (r *gormSampleRepository) read(ctx context.Context) *gorm.DB {
+	return ReadConn(ctx, r.db, r.reader)
+}
+
+func (r *gormSampleRepository) List(ctx context.Context, params pagination.Params) ([]models.Sample, int64, error) {
+	return r.page(r.read(ctx).Model(&models.Sample{}).Preload("User"), params)
+}
+
+func (r *gormSampleRepository) ListByUser(ctx context.Context, userID uint, params pagination.Params) ([]models.Sample, int64, error) {
+	return r.page(r.read(ctx).Model(&models.Sample{}).Where("user_id = ?", userID), params)
+}
+
+func (r *gormSampleRepository) page(query *gorm.DB, params pagination.Params) ([]models.Sample, int64, error) {
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var samples []models.Sample
+	if err := query.
+		Offset(params.Offset()).
+		Limit(params.PerPage).
+		Order(params.OrderClause("created_at", sampleSortableColumns)).
+		Find(&samples).Error; err != nil {
+		return nil, 0, err
+	}
+	return samples, total, nil
+}
+
+func (r *gormSampleRepository) FindByID(ctx context.Context, id uint) (*models.Sample, error) {
+	var sample models.Sample
+	if err := r.read(ctx).First(&sample, id).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &sample, nil
+}
+
+func (r *gormSampleRepository) FindWithDetails(ctx context.Context, id uint) (*models.Sample, error) {
+	var sample models.Sample
+	if err := r.read(ctx).
+		Preload("User").
+		Preload("Images", orderedImages).
+		First(&sample, id).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &sample, nil
+}
+
+func (r *gormSampleRepository) TitleExists(ctx context.Context, title string) (bool, error) {
+	var count int64
+	if err := r.read(ctx).Model(&models.Sample{}).Where("title = ?", title).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *gormSampleRepository) Create(ctx context.Context, sample *models.Sample) error {
+	return Conn(ctx, r.db).Create(sample).Error
+}
+
+func (r *gormSampleRepository) Save(ctx context.Context, sample *models.Sample) error {
+	return Conn(ctx, r.db).Omit(clause.Associations).Save(sample).Error
+}
+
+func (r *gormSampleRepository) Delete(ctx context.Context, sample *models.Sample) ([]string, error) {
+	var publicIDs []string
+	err := Conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.SampleImage{}).
+			Where("sample_id = ?", sample.ID).
+			Pluck("public_id", &publicIDs).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("sample_id = ?", sample.ID).Delete(&models.SampleImage{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(sample).Error
+	})
+	return publicIDs, err
+}
+
+func (r *gormSampleRepository) Lock(ctx context.Context, id uint) error {
+	var sample models.Sample
+	return notFound(Conn(ctx, r.db).Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&sample, id).Error)
+}
+
+func (r *gormSampleRepository) Images(ctx context.Context, sampleID uint) ([]models.SampleImage, error) {
+	var images []models.SampleImage
+	if err := orderedImages(r.read(ctx).Where("sample_id = ?", sampleID)).Find(&images).Error; err != nil {
+		return nil, err
+	}
+	return images, nil
+}
+
+func (r *gormSampleRepository) FindImage(ctx context.Context, sampleID, imageID uint) (*models.SampleImage, error) {
+	var image models.SampleImage
+	if err := r.read(ctx).Where("id = ? AND sample_id = ?", imageID, sampleID).First(&image).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &image, nil
+}
+
+func (r *gormSampleRepository) CountImages(ctx context.Context, sampleID uint) (int64, error) {
+	var count int64
+	err := r.read(ctx).Model(&models.SampleImage{}).Where("sample_id = ?", sampleID).Count(&count).Error
+	return count, err
+}
+
+func (r *gormSampleRepository) CreateImage(ctx context.Context, image *models.SampleImage) error {
+	return Conn(ctx, r.db).Create(image).Error
+}
+
+func (r *gormSampleRepository) SaveImage(ctx context.Context, image *models.SampleImage) error {
+	return Conn(ctx, r.db).Save(image).Error
+}
+
+func (r *gormSampleRepository) DeleteImage(ctx context.Context, image *models.SampleImage) error {
+	return Conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(image).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.SampleImage{}).
+			Where("sample_id = ? AND position > ?", image.SampleID, image.Position).
+			Update("position", gorm.Expr("position - 1")).Error
+	})
+}
+
+func (r *gormSampleRepository) SetImageOrder(ctx context.Context, sampleID uint, imageIDs []uint) error {
+	return Conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		for position, id := range imageIDs {
+			if err := tx.Model(&models.SampleImage{}).
+				Where("id = ? AND sample_id = ?", id, sampleID).
+				Update("position", position).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *gormSampleRepository) SyncCover(ctx context.Context, sampleID uint) error {
+	db := Conn(ctx, r.db)
+
+	var cover models.SampleImage
+	err := orderedImages(db.Where("sample_id = ?", sampleID)).First(&cover).Error
+	if err != nil && notFound(err) != ErrNotFound {
+		return err
+	}
+
+	return db.Model(&models.Sample{}).Where("id = ?", sampleID).Updates(map[string]interface{}{
+		"image_url":       cover.URL,
+		"image_public_id": cover.PublicID,
+	}).Error
+}
+
+func (r *gormSampleRepository) ReferencedAssets(ctx context.Context) ([]string, error) {
+	db := Conn(ctx, r.db)
+
+	var covers, images []string
+	if err := db.
+		Model(&models.Sample{}).
+		Where("image_public_id <> ''").
+		Pluck("image_public_id", &covers).Error; err != nil {
+		return nil, err
+	}
+	if err := db.
+		Model(&models.SampleImage{}).
+		Joins("JOIN samples ON samples.id = sample_images.sample_id AND samples.deleted_at IS NULL").
+		Pluck("sample_images.public_id", &images).Error; err != nil {
+		return nil, err
+	}
+	return append(covers, images...), nil
+}
+
+func (r *gormSampleRepository) ForgetAsset(ctx context.Context, publicID string) error {
+	return Conn(ctx, r.db).Unscoped().
+		Model(&models.Sample{}).
+		Where("deleted_at IS NOT NULL AND image_public_id = ?", publicID).
+		Updates(map[string]interface{}{"image_url": "", "image_public_id": ""}).Error
+}
+
+func orderedImages(db *gorm.DB) *gorm.DB {
+	return db.Order("position ASC, id ASC")
+}
@@ -0,0 +1,56 @@
+package repositories
+
+import (
+	"context"
+
+	"go-fiber-boilerplate/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// UnlockTokenRepository stores the hashes of account unlock tokens.
+type UnlockTokenRepository interface {
+	// Replace stores token as the only pending unlock token of its user.
+	Replace(ctx context.Context, token *models.AccountUnlockToken) error
+	// FindByHashForUpdate also locks the token until the transaction ends.
+	FindByHashForUpdate(ctx context.Context, tokenHash string) (*models.AccountUnlockToken, error)
+	MarkUsed(ctx context.Context, id uint) error
+	DeleteForUser(ctx context.Context, userID uint) error
+}
+
+type gormUnlockTokenRepository struct {
+	db *gorm.DB
+}
+
+func NewUnlockTokenRepository(db *gorm.DB) UnlockTokenRepository {
+	return &gormUnlockTokenRepository{db: db}
+}
+
+func (r *gormUnlockTokenRepository) Replace(ctx context.Context, token *models.AccountUnlockToken) error {
+	return Conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", token.UserID).Delete(&models.AccountUnlockToken{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(token).Error
+	})
+}
+
+func (r *gormUnlockTokenRepository) FindByHashForUpdate(ctx context.Context, tokenHash string) (*models.AccountUnlockToken, error) {
+	var token models.AccountUnlockToken
+	if err := Conn(ctx, r.db).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("token_hash = ?", tokenHash).
+		First(&token).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &token, nil
+}
+
+func (r *gormUnlockTokenRepository) MarkUsed(ctx context.Context, id uint) error {
+	return Conn(ctx, r.db).Model(&models.AccountUnlockToken{}).Where("id = ?", id).Update("used", true).Error
+}
+
+func (r *gormUnlockTokenRepository) DeleteForUser(ctx context.Context, userID uint) error {
+	return Conn(ctx, r.db).Where("user_id = ?", userID).Delete(&models.AccountUnlockToken{}).Error
+}
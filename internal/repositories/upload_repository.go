@@ -0,0 +1,74 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"go-fiber-boilerplate/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// UploadRepository stores the metadata and confirmed offset of tus
+// resumable uploads.
+type UploadRepository interface {
+	Create(ctx context.Context, upload *models.ResumableUpload) error
+	// FindActive returns the upload if it belongs to the user and has not
+	// expired at now.
+	FindActive(ctx context.Context, id string, userID uint, now time.Time) (*models.ResumableUpload, error)
+	// Advance moves the offset of the upload from from to to, marking it
+	// completed when completedAt is set. It reports false when the offset
+	// was no longer from, i.e. another request got there first.
+	Advance(ctx context.Context, id string, from, to int64, completedAt *time.Time) (bool, error)
+	// ExpiredIDs returns the IDs of the uploads expired at now.
+	ExpiredIDs(ctx context.Context, now time.Time) ([]string, error)
+	Delete(ctx context.Context, id string) error
+}
+
+type gormUploadRepository struct {
+	db *gorm.DB
+}
+
+func NewUploadRepository(db *gorm.DB) UploadRepository {
+	return &gormUploadRepository{db: db}
+}
+
+func (r *gormUploadRepository) Create(ctx context.Context, upload *models.ResumableUpload) error {
+	return Conn(ctx, r.db).Create(upload).Error
+}
+
+func (r *gormUploadRepository) FindActive(ctx context.Context, id string, userID uint, now time.Time) (*models.ResumableUpload, error) {
+	var upload models.ResumableUpload
+	if err := Conn(ctx, r.db).
+		Where("id = ? AND user_id = ? AND expires_at > ?", id, userID, now).
+		First(&upload).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &upload, nil
+}
+
+func (r *gormUploadRepository) Advance(ctx context.Context, id string, from, to int64, completedAt *time.Time) (bool, error) {
+	updates := map[string]interface{}{"upload_offset": to}
+	if completedAt != nil {
+		updates["completed_at"] = *completedAt
+	}
+	result := Conn(ctx, r.db).Model(&models.ResumableUpload{}).
+		Where("id = ? AND upload_offset = ?", id, from).
+		Updates(updates)
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *gormUploadRepository) ExpiredIDs(ctx context.Context, now time.Time) ([]string, error) {
+	var ids []string
+	if err := Conn(ctx, r.db).
+		Model(&models.ResumableUpload{}).
+		Where("expires_at <= ?", now).
+		Pluck("id", &ids).Error; err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+func (r *gormUploadRepository) Delete(ctx context.Context, id string) error {
+	return Conn(ctx, r.db).Delete(&models.ResumableUpload{}, "id = ?", id).Error
+}
@@ -0,0 +1,245 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"go-fiber-boilerplate/internal/models"
+	"go-fiber-boilerplate/pkg/pagination"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// UserRepository stores user accounts. Lookups skip soft-deleted users
+// unless stated otherwise.
+type UserRepository interface {
+	// List returns a page of users with their roles and the total count,
+	// filtered by the pagination search and the is_active, verified, role and
+	// deleted (include or only) filters. It is served by a read replica when
+	// the context is marked ReadOnly.
+	List(ctx context.Context, params pagination.Params) ([]models.User, int64, error)
+	FindByID(ctx context.Context, id uint) (*models.User, error)
+	// FindByIDForUpdate also locks the user until the transaction ends.
+	FindByIDForUpdate(ctx context.Context, id uint) (*models.User, error)
+	// FindWithRoles also returns soft-deleted users and loads the roles.
+	FindWithRoles(ctx context.Context, id uint) (*models.User, error)
+	FindByEmail(ctx context.Context, email string) (*models.User, error)
+	// EmailExists reports whether any user, soft-deleted ones included, has
+	// the email. Deleted users keep their address, which stays unique.
+	EmailExists(ctx context.Context, email string) (bool, error)
+	// Create inserts the user and grants it the named roles.
+	Create(ctx context.Context, user *models.User, roles ...string) error
+	SetActive(ctx context.Context, id uint, active bool) error
+	// Delete soft-deletes the user.
+	Delete(ctx context.Context, id uint) error
+	// Restore undoes a soft delete.
+	Restore(ctx context.Context, id uint) error
+	UpdatePassword(ctx context.Context, id uint, passwordHash string) error
+	MarkEmailVerified(ctx context.Context, id uint, at time.Time) error
+	// IncrementTokenVersion invalidates every access token issued to the
+	// user so far.
+	IncrementTokenVersion(ctx context.Context, id uint) error
+	// SetTOTPSecret stores a pending TOTP secret, not yet enabled.
+	SetTOTPSecret(ctx context.Context, id uint, secret string) error
+	// EnableTOTP turns on MFA with the pending secret, step being the last
+	// accepted TOTP time step.
+	EnableTOTP(ctx context.Context, id uint, step int64) error
+	// DisableTOTP turns off MFA and forgets the secret.
+	DisableTOTP(ctx context.Context, id uint) error
+	// SetTOTPLastStep records the last accepted TOTP time step so its code
+	// cannot be replayed.
+	SetTOTPLastStep(ctx context.Context, id uint, step int64) error
+}
+
+var userSortableColumns = map[string]string{
+	"created_at": "created_at",
+	"updated_at": "updated_at",
+	"email":      "email",
+	"first_name": "first_name",
+	"last_name":  "last_name",
+	"id":         "id",
+}
+
+type gormUserRepository struct {
+	db     *gorm.DB
+	reader Reader
+}
+
+// NewUserRepository builds the repository on db. reader may be nil, in which
+// case ReadOnly reads also go to db.
+func NewUserRepository(db *gorm.DB, reader Reader) UserRepository {
+	return &gormUserRepository{db: db, reader: reader}
+}
+
+func (r *gormUserRepository) List(ctx context.Context, params pagination.Params) ([]models.User, int64, error) {
+	query := ReadConn(ctx, r.db, r.reader).Model(&models.User{})
+
+	if deleted, ok := params.Filter("deleted"); ok {
+		switch deleted {
+		case "include":
+			query = query.Unscoped()
+		case "only":
+			query = query.Unscoped().Where("users.deleted_at IS NOT NULL")
+		}
+	}
+
+	if params.Search != "" {
+		like := params.SearchPattern()
+		query = query.Where(
+			`LOWER(users.email) LIKE ? ESCAPE '\' OR LOWER(users.first_name) LIKE ? ESCAPE '\' OR LOWER(users.last_name) LIKE ? ESCAPE '\'`,
+			like, like, like)
+	}
+
+	if active, ok := params.BoolFilter("is_active"); ok {
+		query = query.Where("users.is_active = ?", active)
+	}
+
+	if verified, ok := params.BoolFilter("verified"); ok {
+		if verified {
+			query = query.Where("users.email_verified_at IS NOT NULL")
+		} else {
+			query = query.Where("users.email_verified_at IS NULL")
+		}
+	}
+
+	if role, ok := params.Filter("role"); ok {
+		query = query.Where("EXISTS (SELECT 1 FROM user_roles JOIN roles ON roles.id = user_roles.role_id "+
+			"WHERE user_roles.user_id = users.id AND roles.name = ?)", role)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var users []models.User
+	if err := query.
+		Preload("Roles").
+		Offset(params.Offset()).
+		Limit(params.PerPage).
+		Order(params.OrderClause("created_at", userSortableColumns)).
+		Find(&users).Error; err != nil {
+		return nil, 0, err
+	}
+	return users, total, nil
+}
+
+func (r *gormUserRepository) FindByID(ctx context.Context, id uint) (*models.User, error) {
+	var user models.User
+	if err := Conn(ctx, r.db).First(&user, id).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &user, nil
+}
+
+func (r *gormUserRepository) FindByIDForUpdate(ctx context.Context, id uint) (*models.User, error) {
+	var user models.User
+	if err := Conn(ctx, r.db).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", id).
+		First(&user).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &user, nil
+}
+
+func (r *gormUserRepository) FindWithRoles(ctx context.Context, id uint) (*models.User, error) {
+	var user models.User
+	if err := Conn(ctx, r.db).Unscoped().Preload("Roles").First(&user, id).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &user, nil
+}
+
+func (r *gormUserRepository) FindByEmail(ctx context.Context, email string) (*models.User, error) {
+	var user models.User
+	if err := Conn(ctx, r.db).Where("email = ?", email).First(&user).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &user, nil
+}
+
+func (r *gormUserRepository) EmailExists(ctx context.Context, email string) (bool, error) {
+	var count int64
+	if err := Conn(ctx, r.db).Unscoped().Model(&models.User{}).Where("email = ?", email).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *gormUserRepository) Create(ctx context.Context, user *models.User, roles ...string) error {
+	return Conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+		if len(roles) == 0 {
+			return nil
+		}
+
+		var granted []models.Role
+		if err := tx.Where("name IN ?", roles).Find(&granted).Error; err != nil {
+			return err
+		}
+		if len(granted) != len(roles) {
+			return ErrNotFound
+		}
+		return tx.Model(user).Association("Roles").Append(granted)
+	})
+}
+
+func (r *gormUserRepository) SetActive(ctx context.Context, id uint, active bool) error {
+	return Conn(ctx, r.db).Model(&models.User{}).Where("id = ?", id).Update("is_active", active).Error
+}
+
+func (r *gormUserRepository) Delete(ctx context.Context, id uint) error {
+	return Conn(ctx, r.db).Delete(&models.User{}, id).Error
+}
+
+func (r *gormUserRepository) Restore(ctx context.Context, id uint) error {
+	return Conn(ctx, r.db).Unscoped().Model(&models.User{}).Where("id = ?", id).Update("deleted_at", nil).Error
+}
+
+func (r *gormUserRepository) UpdatePassword(ctx context.Context, id uint, passwordHash string) error {
+	return Conn(ctx, r.db).Model(&models.User{}).Where("id = ?", id).Update("password", passwordHash).Error
+}
+
+func (r *gormUserRepository) MarkEmailVerified(ctx context.Context, id uint, at time.Time) error {
+	return Conn(ctx, r.db).Model(&models.User{}).Where("id = ?", id).Update("email_verified_at", at).Error
+}
+
+func (r *gormUserRepository) SetTOTPSecret(ctx context.Context, id uint, secret string) error {
+	return r.updates(ctx, id, map[string]interface{}{
+		"totp_secret":    secret,
+		"totp_last_step": 0,
+	})
+}
+
+func (r *gormUserRepository) EnableTOTP(ctx context.Context, id uint, step int64) error {
+	return r.updates(ctx, id, map[string]interface{}{
+		"totp_enabled":   true,
+		"totp_last_step": step,
+	})
+}
+
+func (r *gormUserRepository) DisableTOTP(ctx context.Context, id uint) error {
+	return r.updates(ctx, id, map[string]interface{}{
+		"totp_secret":    "",
+		"totp_enabled":   false,
+		"totp_last_step": 0,
+	})
+}
+
+func (r *gormUserRepository) SetTOTPLastStep(ctx context.Context, id uint, step int64) error {
+	return r.updates(ctx, id, map[string]interface{}{"totp_last_step": step})
+}
+
+func (r *gormUserRepository) updates(ctx context.Context, id uint, values map[string]interface{}) error {
+	return Conn(ctx, r.db).Model(&models.User{}).Where("id = ?", id).Updates(values).Error
+}
+
+func (r *gormUserRepository) IncrementTokenVersion(ctx context.Context, id uint) error {
+	return Conn(ctx, r.db).Model(&models.User{}).
+		Where("id = ?", id).
+		Update("token_version", gorm.Expr("token_version + 1")).Error
+}
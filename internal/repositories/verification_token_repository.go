@@ -0,0 +1,53 @@
+package repositories
+
+import (
+	"context"
+
+	"go-fiber-boilerplate/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// VerificationTokenRepository stores the hashes of email verification
+// tokens.
+type VerificationTokenRepository interface {
+	// Replace stores token as the only pending verification token of its
+	// user.
+	Replace(ctx context.Context, token *models.EmailVerificationToken) error
+	// FindByHashForUpdate also locks the token until the transaction ends.
+	FindByHashForUpdate(ctx context.Context, tokenHash string) (*models.EmailVerificationToken, error)
+	MarkUsed(ctx context.Context, id uint) error
+}
+
+type gormVerificationTokenRepository struct {
+	db *gorm.DB
+}
+
+func NewVerificationTokenRepository(db *gorm.DB) VerificationTokenRepository {
+	return &gormVerificationTokenRepository{db: db}
+}
+
+func (r *gormVerificationTokenRepository) Replace(ctx context.Context, token *models.EmailVerificationToken) error {
+	return Conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", token.UserID).Delete(&models.EmailVerificationToken{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(token).Error
+	})
+}
+
+func (r *gormVerificationTokenRepository) FindByHashForUpdate(ctx context.Context, tokenHash string) (*models.EmailVerificationToken, error) {
+	var token models.EmailVerificationToken
+	if err := Conn(ctx, r.db).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("token_hash = ?", tokenHash).
+		First(&token).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &token, nil
+}
+
+func (r *gormVerificationTokenRepository) MarkUsed(ctx context.Context, id uint) error {
+	return Conn(ctx, r.db).Model(&models.EmailVerificationToken{}).Where("id = ?", id).Update("used", true).Error
+}
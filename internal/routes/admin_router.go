@@ -0,0 +1,69 @@
+package routes
+
+import (
+	"go-fiber-boilerplate/config"
+	"go-fiber-boilerplate/internal/controllers"
+	"go-fiber-boilerplate/internal/middlewares"
+	"go-fiber-boilerplate/internal/models"
+	"go-fiber-boilerplate/internal/ratelimit"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func SetupAdminRoutes(api fiber.Router, cfg *config.Config, svc Services, limits ratelimit.Store) {
+	roleController := controllers.NewRoleController(svc.RBAC)
+	adminController := controllers.NewAdminController(svc.Admin)
+	rateLimitController := controllers.NewRateLimitController(cfg, limits)
+
+	requireAuth := middlewares.AuthMiddleware(cfg, svc.Auth)
+
+	admin := api.Group("/admin")
+
+	users := admin.Group("/users")
+	users.Get("/",
+		requireAuth,
+		middlewares.RequirePermission(svc.RBAC, models.PermUsersRead),
+		adminController.ListUsers)
+	users.Get("/:id",
+		requireAuth,
+		middlewares.RequirePermission(svc.RBAC, models.PermUsersRead),
+		adminController.GetUser)
+	users.Get("/:id/samples",
+		requireAuth,
+		middlewares.RequirePermission(svc.RBAC, models.PermUsersRead),
+		adminController.GetUserSamples)
+	users.Patch("/:id/status",
+		requireAuth,
+		middlewares.RequirePermission(svc.RBAC, models.PermUsersManage),
+		adminController.UpdateUserStatus)
+	users.Post("/:id/password-reset",
+		requireAuth,
+		middlewares.RequirePermission(svc.RBAC, models.PermUsersManage),
+		adminController.ForcePasswordReset)
+	users.Post("/:id/unlock",
+		requireAuth,
+		middlewares.RequirePermission(svc.RBAC, models.PermUsersManage),
+		adminController.UnlockUser)
+	users.Delete("/:id",
+		requireAuth,
+		middlewares.RequirePermission(svc.RBAC, models.PermUsersManage),
+		adminController.DeleteUser)
+	users.Post("/:id/restore",
+		requireAuth,
+		middlewares.RequirePermission(svc.RBAC, models.PermUsersManage),
+		adminController.RestoreUser)
+
+	admin.Get("/roles",
+		requireAuth,
+		middlewares.RequirePermission(svc.RBAC, models.PermRolesManage),
+		roleController.GetRoles)
+	users.Put("/:id/roles",
+		requireAuth,
+		middlewares.RequirePermission(svc.RBAC, models.PermRolesManage),
+		roleController.SetUserRoles)
+
+	admin.Get("/rate-limit",
+		requireAuth,
+		middlewares.RequirePermission(svc.RBAC, models.PermSystemRead),
+		rateLimitController.Stats)
+}
@@ -6,32 +6,82 @@ import (
 	"go-fiber-boilerplate/config"
 	"go-fiber-boilerplate/internal/controllers"
 	"go-fiber-boilerplate/internal/middlewares"
+	"go-fiber-boilerplate/internal/ratelimit"
 
 	"github.com/gofiber/fiber/v2"
 )
 
-func SetupAuthRoutes(api fiber.Router, cfg *config.Config) {
-	authController := controllers.NewAuthController(cfg)
+func SetupAuthRoutes(api fiber.Router, cfg *config.Config, svc Services, limits ratelimit.Store) {
+	authController := controllers.NewAuthController(svc.Auth)
+	mfaController := controllers.NewMFAController(svc.MFA)
+
+	requireAuth := middlewares.AuthMiddleware(cfg, svc.Auth)
 
 	auth := api.Group("/auth")
 
 	keyGen := func(c *fiber.Ctx) string {
-		return c.IP()
+		return middlewares.ClientIP(c)
+	}
+
+	// Credential checks use the exact sliding log, refresh a token bucket so
+	// clients with several tabs can burst, and the rest the sliding counter.
+	// Every auth route fails closed: without the limiter they are open to
+	// credential and token guessing, so an unavailable store answers 503.
+	perMinute := func(algorithm ratelimit.Algorithm, max int) fiber.Handler {
+		return middlewares.RateLimitMiddleware(limits, ratelimit.Limit{
+			Algorithm:  algorithm,
+			Max:        max,
+			Window:     time.Minute,
+			FailClosed: true,
+		}, keyGen)
 	}
 
 	auth.Post("/register",
-		middlewares.RateLimitMiddleware(5, time.Minute, keyGen),
+		perMinute(ratelimit.SlidingWindowCounter, 5),
 		authController.Register)
 
 	auth.Post("/login",
-		middlewares.RateLimitMiddleware(10, time.Minute, keyGen),
+		perMinute(ratelimit.SlidingWindowLog, 10),
 		authController.Login)
 
+	auth.Post("/mfa/verify",
+		perMinute(ratelimit.SlidingWindowLog, 5),
+		authController.VerifyMFA)
+
+	auth.Post("/mfa/enroll", requireAuth, mfaController.Enroll)
+	auth.Post("/mfa/confirm",
+		perMinute(ratelimit.SlidingWindowLog, 5),
+		requireAuth,
+		mfaController.Confirm)
+	auth.Post("/mfa/disable",
+		perMinute(ratelimit.SlidingWindowLog, 5),
+		requireAuth,
+		mfaController.Disable)
+
+	auth.Post("/refresh",
+		perMinute(ratelimit.TokenBucket, 30),
+		authController.Refresh)
+
+	auth.Post("/logout", requireAuth, authController.Logout)
+	auth.Post("/logout-all", requireAuth, authController.LogoutAll)
+
+	auth.Post("/verify-email",
+		perMinute(ratelimit.SlidingWindowCounter, 10),
+		authController.VerifyEmail)
+
+	auth.Post("/unlock-account",
+		perMinute(ratelimit.SlidingWindowCounter, 10),
+		authController.UnlockAccount)
+
+	auth.Post("/resend-verification",
+		perMinute(ratelimit.SlidingWindowCounter, 5),
+		authController.ResendVerification)
+
 	auth.Post("/forgot-password",
-		middlewares.RateLimitMiddleware(5, time.Minute, keyGen),
+		perMinute(ratelimit.SlidingWindowCounter, 5),
 		authController.ForgotPassword)
 
 	auth.Post("/reset-password",
-		middlewares.RateLimitMiddleware(5, time.Minute, keyGen),
+		perMinute(ratelimit.SlidingWindowCounter, 5),
 		authController.ResetPassword)
 }
@@ -1,20 +1,47 @@
 package routes
 
 import (
-    "go-fiber-boilerplate/config"
-    "github.com/gofiber/fiber/v2"
+	"go-fiber-boilerplate/config"
+	"go-fiber-boilerplate/internal/middlewares"
+	"go-fiber-boilerplate/internal/ratelimit"
+	"go-fiber-boilerplate/internal/services"
+
+	"github.com/gofiber/fiber/v2"
 )
 
-func SetupRoutes(app *fiber.App, cfg *config.Config) {
-    app.Get("/api/health", func(c *fiber.Ctx) error {
-        return c.JSON(fiber.Map{
-            "status":  "ok", 
-            "message": "Server is running",
-        })
-    })
+// Services are the services the handlers are built on, constructed once in
+// cmd/main.go.
+type Services struct {
+	Auth    *services.AuthService
+	MFA     *services.MFAService
+	Admin   *services.AdminService
+	RBAC    *services.RBACService
+	Samples *services.SampleService
+	Uploads *services.UploadService
+}
+
+// SetupRoutes registers every route. The app must be created with
+// StreamRequestBody and DisablePreParseMultipartForm: tus chunks under
+// uploadPath are streamed to disk and all other bodies are held to the
+// BodyLimit by middlewares.BodyLimit.
+func SetupRoutes(app *fiber.App, cfg *config.Config, svc Services, limits ratelimit.Store) {
+	app.Use(middlewares.BodyLimit(uploadPath))
+
+	app.Get("/api/health", func(c *fiber.Ctx) error {
+		return c.JSON(fiber.Map{
+			"status":  "ok",
+			"message": "Server is running",
+		})
+	})
+
+	if cfg.StorageBackend == config.StorageLocal {
+		app.Static("/uploads", cfg.StorageLocalDir)
+	}
 
-    api := app.Group("/")
+	api := app.Group("/")
 
-    SetupAuthRoutes(api, cfg)
-	SetupSampleRoutes(api, cfg)
-}
\ No newline at end of file
+	SetupAuthRoutes(api, cfg, svc, limits)
+	SetupSampleRoutes(api, cfg, svc)
+	SetupUploadRoutes(api, cfg, svc)
+	SetupAdminRoutes(api, cfg, svc, limits)
+}
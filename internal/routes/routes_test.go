@@ -0,0 +1,277 @@
+package routes
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"go-fiber-boilerplate/config"
+	"go-fiber-boilerplate/database"
+	"go-fiber-boilerplate/internal/middlewares"
+	"go-fiber-boilerplate/internal/ratelimit"
+	"go-fiber-boilerplate/internal/repositories"
+	"go-fiber-boilerplate/internal/services"
+	"go-fiber-boilerplate/internal/storage"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const testPassword = "Passw0rd!x"
+
+// newTestApp builds the app the way cmd/main.go does, on an in-memory SQLite
+// database with local storage.
+func newTestApp(t *testing.T) *fiber.App {
+	t.Helper()
+
+	for key, value := range map[string]string{
+		"DB_DRIVER":                  config.DBDriverSQLite,
+		"DB_SQLITE_PATH":             ":memory:",
+		"PORT":                       "0",
+		"JWT_SECRET":                 "test-jwt-secret",
+		"RESET_TOKEN_SECRET":         "test-reset-secret",
+		"MFA_ENCRYPTION_KEY":         "test-mfa-key",
+		"CORS_ALLOWED_ORIGINS":       "http://localhost:3000",
+		"CORS_ALLOW_CREDENTIALS":     "false",
+		"SMTP_HOST":                  "127.0.0.1",
+		"SMTP_PORT":                  "1",
+		"SMTP_USERNAME":              "test",
+		"SMTP_PASSWORD":              "test",
+		"FROM_EMAIL":                 "noreply@example.com",
+		"FRONTEND_URL":               "http://localhost:3000",
+		"REQUIRE_EMAIL_VERIFICATION": "false",
+		"STORAGE_BACKEND":            config.StorageLocal,
+		"STORAGE_LOCAL_DIR":          t.TempDir(),
+		"TUS_UPLOAD_DIR":             t.TempDir(),
+		"RATE_LIMIT_STORE":           config.RateLimitStoreMemory,
+	} {
+		t.Setenv(key, value)
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+
+	db := database.ConnectDB(cfg)
+	t.Cleanup(func() { database.CloseDB(db) })
+
+	store, err := storage.New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	limits, err := ratelimit.New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { limits.Close() })
+
+	replicas := database.OpenReplicas(cfg, db)
+	transactor := repositories.NewTransactor(db)
+	users := repositories.NewUserRepository(db, replicas)
+	samples := repositories.NewSampleRepository(db, replicas)
+	recoveryCodes := repositories.NewRecoveryCodeRepository(db)
+	pendingDeletions := repositories.NewPendingDeletionRepository(db)
+
+	rbacService := services.NewRBACService(transactor, users, repositories.NewRoleRepository(db))
+	uploadService := services.NewUploadService(cfg, repositories.NewUploadRepository(db))
+	authService := services.NewAuthService(cfg, services.AuthRepositories{
+		Transactor:         transactor,
+		Users:              users,
+		ResetTokens:        repositories.NewResetTokenRepository(db),
+		RefreshTokens:      repositories.NewRefreshTokenRepository(db),
+		RevokedTokens:      repositories.NewRevokedTokenRepository(db),
+		VerificationTokens: repositories.NewVerificationTokenRepository(db),
+		UnlockTokens:       repositories.NewUnlockTokenRepository(db),
+		LoginThrottles:     repositories.NewLoginThrottleRepository(db),
+		RecoveryCodes:      recoveryCodes,
+	})
+	t.Cleanup(authService.Wait)
+
+	app := fiber.New(fiber.Config{
+		ErrorHandler:                 middlewares.ErrorHandler,
+		StreamRequestBody:            true,
+		DisablePreParseMultipartForm: true,
+	})
+	app.Use(middlewares.RequestIDMiddleware())
+	app.Use(middlewares.ClientIPMiddleware(cfg))
+	SetupRoutes(app, cfg, Services{
+		Auth:    authService,
+		MFA:     services.NewMFAService(cfg, transactor, users, recoveryCodes, authService),
+		Admin:   services.NewAdminService(cfg, transactor, users, authService, samples),
+		RBAC:    rbacService,
+		Samples: services.NewSampleService(cfg, transactor, samples, pendingDeletions, store, rbacService, uploadService),
+		Uploads: uploadService,
+	}, limits)
+	return app
+}
+
+type testResponse struct {
+	Data struct {
+		ID    uint   `json:"id"`
+		Token string `json:"token"`
+		Title string `json:"title"`
+	} `json:"data"`
+	Code string `json:"code"`
+}
+
+// call sends a JSON request, authorised with token unless it is empty, and
+// returns the status and decoded body.
+func call(t *testing.T, app *fiber.App, method, path, token string, body interface{}) (int, testResponse) {
+	t.Helper()
+
+	var payload bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&payload).Encode(body); err != nil {
+			t.Fatal(err)
+		}
+	}
+	req := httptest.NewRequest(method, path, &payload)
+	req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	if token != "" {
+		req.Header.Set(fiber.HeaderAuthorization, "Bearer "+token)
+	}
+
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	var decoded testResponse
+	if err := json.NewDecoder(resp.Body).Decode(&decoded); err != nil {
+		t.Fatalf("%s %s: decoding response: %v", method, path, err)
+	}
+	return resp.StatusCode, decoded
+}
+
+// signUp registers the email and returns an access token for it.
+func signUp(t *testing.T, app *fiber.App, email string) string {
+	t.Helper()
+
+	status, resp := call(t, app, "POST", "/auth/register", "", fiber.Map{
+		"email":      email,
+		"password":   testPassword,
+		"first_name": "Test",
+		"last_name":  "User",
+	})
+	if status != fiber.StatusCreated {
+		t.Fatalf("register %s: status = %d (%s), want 201", email, status, resp.Code)
+	}
+
+	status, resp = call(t, app, "POST", "/auth/login", "", fiber.Map{"email": email, "password": testPassword})
+	if status != fiber.StatusOK || resp.Data.Token == "" {
+		t.Fatalf("login %s: status = %d (%s), want 200 with a token", email, status, resp.Code)
+	}
+	return resp.Data.Token
+}
+
+func TestAuthFlow(t *testing.T) {
+	app := newTestApp(t)
+	signUp(t, app, "alice@example.com")
+
+	status, resp := call(t, app, "POST", "/auth/register", "", fiber.Map{
+		"email":      "alice@example.com",
+		"password":   testPassword,
+		"first_name": "Other",
+		"last_name":  "User",
+	})
+	if status != fiber.StatusConflict || resp.Code != "email_taken" {
+		t.Errorf("duplicate register: status = %d (%s), want 409 email_taken", status, resp.Code)
+	}
+
+	status, resp = call(t, app, "POST", "/auth/login", "", fiber.Map{"email": "alice@example.com", "password": "Wr0ngPass!"})
+	if status != fiber.StatusUnauthorized {
+		t.Errorf("login with a wrong password: status = %d (%s), want 401", status, resp.Code)
+	}
+
+	if status, _ := call(t, app, "POST", "/samples", "", fiber.Map{"title": "Anonymous"}); status != fiber.StatusUnauthorized {
+		t.Errorf("create sample without a token: status = %d, want 401", status)
+	}
+}
+
+func TestSampleCRUD(t *testing.T) {
+	app := newTestApp(t)
+	owner := signUp(t, app, "owner@example.com")
+	other := signUp(t, app, "other@example.com")
+
+	status, created := call(t, app, "POST", "/samples", owner, fiber.Map{"title": "Sunset", "description": "first"})
+	if status != fiber.StatusCreated || created.Data.ID == 0 {
+		t.Fatalf("create: status = %d (%s), want 201", status, created.Code)
+	}
+	path := fmt.Sprintf("/samples/%d", created.Data.ID)
+
+	if status, resp := call(t, app, "POST", "/samples", other, fiber.Map{"title": "Sunset"}); status != fiber.StatusConflict {
+		t.Errorf("create with a taken title: status = %d (%s), want 409", status, resp.Code)
+	}
+
+	if status, resp := call(t, app, "GET", path, owner, nil); status != fiber.StatusOK || resp.Data.Title != "Sunset" {
+		t.Errorf("get: status = %d, title %q; want 200 Sunset", status, resp.Data.Title)
+	}
+
+	if status, resp := call(t, app, "PATCH", path, other, fiber.Map{"title": "Mine"}); status != fiber.StatusForbidden {
+		t.Errorf("update by another user: status = %d (%s), want 403", status, resp.Code)
+	}
+	if status, resp := call(t, app, "PATCH", path, owner, fiber.Map{"title": "Sunrise"}); status != fiber.StatusOK || resp.Data.Title != "Sunrise" {
+		t.Errorf("update: status = %d (%s), title %q; want 200 Sunrise", status, resp.Code, resp.Data.Title)
+	}
+
+	if status, resp := call(t, app, "DELETE", path, other, nil); status != fiber.StatusForbidden {
+		t.Errorf("delete by another user: status = %d (%s), want 403", status, resp.Code)
+	}
+	if status, resp := call(t, app, "DELETE", path, owner, nil); status != fiber.StatusOK {
+		t.Fatalf("delete: status = %d (%s), want 200", status, resp.Code)
+	}
+	if status, _ := call(t, app, "GET", path, owner, nil); status != fiber.StatusNotFound {
+		t.Errorf("get after delete: status = %d, want 404", status)
+	}
+}
+
+func TestTusChunkAboveBodyLimit(t *testing.T) {
+	app := newTestApp(t)
+	token := signUp(t, app, "uploader@example.com")
+
+	size := fiber.DefaultBodyLimit + 1024*1024
+	req := httptest.NewRequest("POST", "/files", nil)
+	req.Header.Set("Tus-Resumable", middlewares.TusVersion)
+	req.Header.Set("Upload-Length", strconv.Itoa(size))
+	req.Header.Set(fiber.HeaderAuthorization, "Bearer "+token)
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	location := resp.Header.Get(fiber.HeaderLocation)
+	if resp.StatusCode != fiber.StatusCreated || location == "" {
+		t.Fatalf("create upload: status = %d, location %q; want 201", resp.StatusCode, location)
+	}
+
+	// One chunk larger than the 4MB body limit of the other routes.
+	req = httptest.NewRequest("PATCH", location, bytes.NewReader(make([]byte, size)))
+	req.Header.Set("Tus-Resumable", middlewares.TusVersion)
+	req.Header.Set("Upload-Offset", "0")
+	req.Header.Set(fiber.HeaderContentType, "application/offset+octet-stream")
+	req.Header.Set(fiber.HeaderAuthorization, "Bearer "+token)
+	resp, err = app.Test(req, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != fiber.StatusNoContent || resp.Header.Get("Upload-Offset") != strconv.Itoa(size) {
+		t.Errorf("patch: status = %d, offset %s; want 204 at %d", resp.StatusCode, resp.Header.Get("Upload-Offset"), size)
+	}
+
+	// Routes that read the whole body keep the limit.
+	req = httptest.NewRequest("POST", "/auth/login", bytes.NewReader(make([]byte, size)))
+	req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	resp, err = app.Test(req, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != fiber.StatusRequestEntityTooLarge {
+		t.Errorf("oversized login body: status = %d, want 413", resp.StatusCode)
+	}
+}
@@ -8,20 +8,33 @@ import (
 	"github.com/gofiber/fiber/v2"
 )
 
-func SetupSampleRoutes(api fiber.Router, cfg *config.Config) {
-	sampleController := controllers.NewSampleController(cfg)
+func SetupSampleRoutes(api fiber.Router, cfg *config.Config, svc Services) {
+	sampleController := controllers.NewSampleController(svc.Samples)
+
+	requireAuth := middlewares.AuthMiddleware(cfg, svc.Auth)
 
 	samples := api.Group("/samples")
 
 	samples.Get("/", sampleController.GetSamples)
-	samples.Get("/:id", middlewares.AuthMiddleware(cfg), sampleController.GetSampleById)
+	samples.Get("/:id", requireAuth, sampleController.GetSampleById)
 	samples.Post("/",
-		middlewares.AuthMiddleware(cfg),
+		requireAuth,
 		middlewares.NewUploaderMiddleware().ImageUpload(2, []string{"image/jpeg", "image/png"}),
 		sampleController.CreateSample)
 	samples.Patch("/:id",
-		middlewares.AuthMiddleware(cfg),
+		requireAuth,
 		middlewares.NewUploaderMiddleware().ImageUpload(2, []string{"image/jpeg", "image/png"}),
 		sampleController.UpdateSample)
-	samples.Delete("/:id", middlewares.AuthMiddleware(cfg), sampleController.DeleteSample)
+	samples.Put("/:id/image", requireAuth, sampleController.AttachImage)
+	samples.Post("/:id/images",
+		requireAuth,
+		middlewares.NewUploaderMiddleware().ImageUpload(2, []string{"image/jpeg", "image/png"}),
+		sampleController.AddImage)
+	samples.Put("/:id/images/order", requireAuth, sampleController.ReorderImages)
+	samples.Put("/:id/images/:imageId",
+		requireAuth,
+		middlewares.NewUploaderMiddleware().ImageUpload(2, []string{"image/jpeg", "image/png"}),
+		sampleController.ReplaceImage)
+	samples.Delete("/:id/images/:imageId", requireAuth, sampleController.DeleteImage)
+	samples.Delete("/:id", requireAuth, sampleController.DeleteSample)
 }
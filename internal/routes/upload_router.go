@@ -0,0 +1,27 @@
+package routes
+
+import (
+	"go-fiber-boilerplate/config"
+	"go-fiber-boilerplate/internal/controllers"
+	"go-fiber-boilerplate/internal/middlewares"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// uploadPath is where the tus endpoints live. Their PATCH bodies are streamed
+// rather than held to the app's BodyLimit.
+const uploadPath = "/files"
+
+func SetupUploadRoutes(api fiber.Router, cfg *config.Config, svc Services) {
+	uploadController := controllers.NewUploadController(cfg, svc.Uploads)
+
+	requireAuth := middlewares.AuthMiddleware(cfg, svc.Auth)
+
+	files := api.Group(uploadPath, middlewares.TusResumable())
+
+	files.Options("/", uploadController.Options)
+	files.Post("/", requireAuth, uploadController.Create)
+	files.Head("/:id", requireAuth, uploadController.Head)
+	files.Patch("/:id", requireAuth, uploadController.Patch)
+	files.Delete("/:id", requireAuth, uploadController.Delete)
+}
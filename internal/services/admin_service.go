@@ -0,0 +1,178 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"go-fiber-boilerplate/config"
+	"go-fiber-boilerplate/internal/models"
+	"go-fiber-boilerplate/internal/repositories"
+	"go-fiber-boilerplate/pkg/apperror"
+	"go-fiber-boilerplate/pkg/pagination"
+)
+
+type AdminService struct {
+	cfg         *config.Config
+	transactor  repositories.Transactor
+	users       repositories.UserRepository
+	authService *AuthService
+	samples     repositories.SampleRepository
+}
+
+func NewAdminService(
+	cfg *config.Config,
+	transactor repositories.Transactor,
+	users repositories.UserRepository,
+	authService *AuthService,
+	samples repositories.SampleRepository,
+) *AdminService {
+	return &AdminService{
+		cfg:         cfg,
+		transactor:  transactor,
+		users:       users,
+		authService: authService,
+		samples:     samples,
+	}
+}
+
+// ListUsers returns users matching the pagination search and filters:
+// is_active, verified, role and deleted (include or only). It may be served
+// by a read replica.
+func (s *AdminService) ListUsers(params pagination.Params) ([]models.User, pagination.Meta, error) {
+	users, total, err := s.users.List(repositories.ReadOnly(context.Background()), params)
+	if err != nil {
+		return nil, pagination.Meta{}, apperror.ErrInternal.Wrap(err)
+	}
+
+	return users, pagination.BuildMeta(total, params), nil
+}
+
+// GetUser returns a user, including soft-deleted ones.
+func (s *AdminService) GetUser(id int) (*models.User, error) {
+	user, err := s.users.FindWithRoles(context.Background(), uint(id))
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, apperror.ErrInternal.Wrap(err)
+	}
+	return user, nil
+}
+
+func (s *AdminService) GetUserSamples(id int, params pagination.Params) ([]models.Sample, pagination.Meta, error) {
+	if _, err := s.GetUser(id); err != nil {
+		return nil, pagination.Meta{}, err
+	}
+
+	samples, total, err := s.samples.ListByUser(repositories.ReadOnly(context.Background()), uint(id), params)
+	if err != nil {
+		return nil, pagination.Meta{}, apperror.ErrInternal.Wrap(err)
+	}
+
+	return samples, pagination.BuildMeta(total, params), nil
+}
+
+// SetActive activates or deactivates an account. Deactivating also revokes
+// every session of the user.
+func (s *AdminService) SetActive(actorID uint, id int, active bool) (*models.User, error) {
+	if uint(id) == actorID && !active {
+		return nil, ErrSelfDeactivation
+	}
+
+	user, err := s.GetUser(id)
+	if err != nil {
+		return nil, err
+	}
+	if user.DeletedAt.Valid {
+		return nil, ErrUserDeleted
+	}
+
+	err = s.transactor.Transaction(context.Background(), func(ctx context.Context) error {
+		if err := s.users.SetActive(ctx, user.ID, active); err != nil {
+			return err
+		}
+		if !active {
+			return s.authService.revokeSessions(ctx, user.ID)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, apperror.ErrInternal.Wrap(err)
+	}
+
+	return s.GetUser(id)
+}
+
+// ForcePasswordReset signs the user out everywhere and emails a reset link.
+func (s *AdminService) ForcePasswordReset(id int) error {
+	user, err := s.GetUser(id)
+	if err != nil {
+		return err
+	}
+	if user.DeletedAt.Valid {
+		return ErrUserDeleted
+	}
+
+	if err := s.transactor.Transaction(context.Background(), func(ctx context.Context) error {
+		return s.authService.revokeSessions(ctx, user.ID)
+	}); err != nil {
+		return apperror.ErrInternal.Wrap(err)
+	}
+
+	return s.authService.SendPasswordResetEmail(*user)
+}
+
+// UnlockUser lifts a login lockout or backoff on the user's email address.
+func (s *AdminService) UnlockUser(id int) error {
+	user, err := s.GetUser(id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.transactor.Transaction(context.Background(), func(ctx context.Context) error {
+		return s.authService.clearLockout(ctx, *user)
+	}); err != nil {
+		return apperror.ErrInternal.Wrap(err)
+	}
+	return nil
+}
+
+// DeleteUser soft-deletes an account and revokes its sessions.
+func (s *AdminService) DeleteUser(actorID uint, id int) error {
+	if uint(id) == actorID {
+		return ErrSelfDeactivation
+	}
+
+	user, err := s.GetUser(id)
+	if err != nil {
+		return err
+	}
+	if user.DeletedAt.Valid {
+		return nil
+	}
+
+	if err := s.transactor.Transaction(context.Background(), func(ctx context.Context) error {
+		if err := s.authService.revokeSessions(ctx, user.ID); err != nil {
+			return err
+		}
+		return s.users.Delete(ctx, user.ID)
+	}); err != nil {
+		return apperror.ErrInternal.Wrap(err)
+	}
+	return nil
+}
+
+func (s *AdminService) RestoreUser(id int) (*models.User, error) {
+	user, err := s.GetUser(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.DeletedAt.Valid {
+		if err := s.users.Restore(context.Background(), user.ID); err != nil {
+			return nil, apperror.ErrInternal.Wrap(err)
+		}
+	}
+
+	return s.GetUser(id)
+}
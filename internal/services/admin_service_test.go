@@ -0,0 +1,52 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"go-fiber-boilerplate/pkg/pagination"
+)
+
+func TestAdminUserLifecycle(t *testing.T) {
+	auth, repos := newTestAuthService(t)
+	svc := NewAdminService(testConfig(), repos.Transactor, repos.Users, auth, repos.Samples)
+
+	admin := register(t, auth, "admin@example.com")
+	user := register(t, auth, "bob@example.com")
+	id := int(user.ID)
+
+	if _, err := svc.SetActive(admin.ID, int(admin.ID), false); !errors.Is(err, ErrSelfDeactivation) {
+		t.Errorf("SetActive(self, false) error = %v, want ErrSelfDeactivation", err)
+	}
+	deactivated, err := svc.SetActive(admin.ID, id, false)
+	if err != nil || deactivated.IsActive {
+		t.Fatalf("SetActive(false) = %+v, %v; want an inactive user", deactivated, err)
+	}
+
+	inactive := pagination.Params{Page: 1, PerPage: 10, Filters: map[string]string{"is_active": "false"}}
+	if users, meta, err := svc.ListUsers(inactive); err != nil || meta.Total != 1 || users[0].ID != user.ID {
+		t.Errorf("ListUsers(is_active=false) = %d users, %v; want only %s", meta.Total, err, user.Email)
+	}
+
+	if err := svc.DeleteUser(admin.ID, id); err != nil {
+		t.Fatalf("DeleteUser() error = %v", err)
+	}
+	if _, meta, _ := svc.ListUsers(pagination.Params{Page: 1, PerPage: 10}); meta.Total != 1 {
+		t.Errorf("ListUsers() after delete total = %d, want the deleted user hidden", meta.Total)
+	}
+	deleted := pagination.Params{Page: 1, PerPage: 10, Filters: map[string]string{"deleted": "only"}}
+	if users, meta, err := svc.ListUsers(deleted); err != nil || meta.Total != 1 || users[0].ID != user.ID {
+		t.Errorf("ListUsers(deleted=only) = %d users, %v; want only %s", meta.Total, err, user.Email)
+	}
+	if _, err := svc.SetActive(admin.ID, id, true); !errors.Is(err, ErrUserDeleted) {
+		t.Errorf("SetActive() on a deleted user error = %v, want ErrUserDeleted", err)
+	}
+
+	restored, err := svc.RestoreUser(id)
+	if err != nil || restored.DeletedAt.Valid {
+		t.Fatalf("RestoreUser() = %+v, %v; want the user back", restored, err)
+	}
+	if _, err := svc.GetUser(999); !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("GetUser(unknown) error = %v, want ErrUserNotFound", err)
+	}
+}
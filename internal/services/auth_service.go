@@ -1,42 +1,90 @@
 package services
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log"
 	"strings"
+	"sync"
 	"time"
 
 	"go-fiber-boilerplate/config"
-	"go-fiber-boilerplate/database"
 	"go-fiber-boilerplate/internal/models"
+	"go-fiber-boilerplate/internal/repositories"
+	"go-fiber-boilerplate/pkg/apperror"
 	"go-fiber-boilerplate/utils"
-
-	"gorm.io/gorm"
-	"gorm.io/gorm/clause"
 )
 
+// AuthService keeps all of its state behind repositories, so it runs on GORM
+// in production and on the fakes of package memory in tests.
 type AuthService struct {
-	cfg *config.Config
+	cfg                *config.Config
+	transactor         repositories.Transactor
+	users              repositories.UserRepository
+	resetTokens        repositories.ResetTokenRepository
+	refreshTokens      repositories.RefreshTokenRepository
+	revokedTokens      repositories.RevokedTokenRepository
+	verificationTokens repositories.VerificationTokenRepository
+	unlockTokens       repositories.UnlockTokenRepository
+	throttles          repositories.LoginThrottleRepository
+	recoveryCodes      repositories.RecoveryCodeRepository
+
+	// background tracks emails sent after the response, see Wait.
+	background sync.WaitGroup
 }
 
-func NewAuthService(cfg *config.Config) *AuthService {
-	return &AuthService{cfg: cfg}
+// AuthRepositories are the repositories AuthService works with.
+type AuthRepositories struct {
+	Transactor         repositories.Transactor
+	Users              repositories.UserRepository
+	ResetTokens        repositories.ResetTokenRepository
+	RefreshTokens      repositories.RefreshTokenRepository
+	RevokedTokens      repositories.RevokedTokenRepository
+	VerificationTokens repositories.VerificationTokenRepository
+	UnlockTokens       repositories.UnlockTokenRepository
+	LoginThrottles     repositories.LoginThrottleRepository
+	RecoveryCodes      repositories.RecoveryCodeRepository
 }
 
-func (s *AuthService) Register(req models.CreateUserRequest) (*models.RegisterResponse, error) {
-	if err := validateRegisterRequest(req); err != nil {
-		return nil, err
+const mfaTokenTTL = 5 * time.Minute
+
+func NewAuthService(cfg *config.Config, repos AuthRepositories) *AuthService {
+	return &AuthService{
+		cfg:                cfg,
+		transactor:         repos.Transactor,
+		users:              repos.Users,
+		resetTokens:        repos.ResetTokens,
+		refreshTokens:      repos.RefreshTokens,
+		revokedTokens:      repos.RevokedTokens,
+		verificationTokens: repos.VerificationTokens,
+		unlockTokens:       repos.UnlockTokens,
+		throttles:          repos.LoginThrottles,
+		recoveryCodes:      repos.RecoveryCodes,
 	}
+}
+
+// Wait blocks until the emails still being sent in the background are done,
+// so shutdown does not close the database under them.
+func (s *AuthService) Wait() {
+	s.background.Wait()
+}
+
+func (s *AuthService) Register(req models.CreateUserRequest) (*models.RegisterResponse, error) {
+	ctx := context.Background()
 
-	var existingUser models.User
-	if err := database.GetDB().Where("email = ?", req.Email).First(&existingUser).Error; err == nil {
-		return nil, errors.New("user with this email already exists")
+	// Soft-deleted accounts keep their email, so they count as taken too.
+	taken, err := s.users.EmailExists(ctx, req.Email)
+	if err != nil {
+		return nil, apperror.ErrInternal.Wrap(err)
+	}
+	if taken {
+		return nil, ErrEmailTaken
 	}
 
 	hashedPassword, err := utils.HashPassword(req.Password)
 	if err != nil {
-		return nil, err
+		return nil, apperror.ErrInternal.Wrap(err)
 	}
 
 	user := models.User{
@@ -47,8 +95,12 @@ func (s *AuthService) Register(req models.CreateUserRequest) (*models.RegisterRe
 		IsActive:  true,
 	}
 
-	if err := database.GetDB().Create(&user).Error; err != nil {
-		return nil, err
+	if err := s.users.Create(ctx, &user, models.RoleUser); err != nil {
+		return nil, apperror.ErrInternal.Wrap(err)
+	}
+
+	if err := s.sendVerificationEmail(user); err != nil {
+		log.Printf("failed to send verification email to %s: %v", user.Email, err)
 	}
 
 	return &models.RegisterResponse{
@@ -56,96 +108,380 @@ func (s *AuthService) Register(req models.CreateUserRequest) (*models.RegisterRe
 	}, nil
 }
 
+// Login checks the credentials and returns tokens, or an mfa pending token
+// when the account has MFA enabled. Failed attempts are throttled per email
+// address; see login_throttle.go.
 func (s *AuthService) Login(req models.LoginRequest) (*models.LoginResponse, error) {
-	if err := validateLoginRequest(req); err != nil {
+	if err := s.checkLoginThrottle(context.Background(), req.Email); err != nil {
 		return nil, err
 	}
 
-	var user models.User
-	if err := database.GetDB().Where("email = ?", req.Email).First(&user).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return nil, errors.New("invalid credentials")
+	user, err := s.users.FindByEmail(context.Background(), req.Email)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			utils.CheckPassword(req.Password, dummyPasswordHash())
+			return nil, s.loginFailed(req.Email, nil, ErrInvalidCredentials)
 		}
 		log.Printf("login database error for %s: %v", req.Email, err)
-		return nil, errors.New("invalid credentials")
+		return nil, ErrInvalidCredentials
 	}
 
 	if !utils.CheckPassword(req.Password, user.Password) {
-		return nil, errors.New("invalid credentials")
+		return nil, s.loginFailed(req.Email, user, ErrInvalidCredentials)
+	}
+
+	// With MFA the failures are only forgotten once the second factor is
+	// verified too, so knowing the password does not reset the code guesses.
+	if !user.TOTPEnabled {
+		if err := s.clearLoginThrottle(context.Background(), req.Email); err != nil {
+			log.Printf("warning: failed to clear login throttle for %s: %v", user.Email, err)
+		}
 	}
 
 	if !user.IsActive {
 		log.Printf("login blocked for inactive account: %s", user.Email)
-		return nil, errors.New("invalid credentials")
+		return nil, ErrInvalidCredentials
+	}
+
+	if s.cfg.RequireEmailVerification && user.EmailVerifiedAt == nil {
+		return nil, ErrEmailNotVerified
+	}
+
+	if user.TOTPEnabled {
+		mfaToken, err := utils.GenerateMFAToken(user.ID, user.Email, user.TokenVersion, s.cfg.JWTSecret, s.cfg.JWTIssuer, s.cfg.JWTAudience, mfaTokenTTL)
+		if err != nil {
+			log.Printf("login mfa token generation failed for %s: %v", user.Email, err)
+			return nil, ErrInvalidCredentials
+		}
+
+		return &models.LoginResponse{
+			MFARequired: true,
+			MFAToken:    mfaToken,
+			ExpiresIn:   int64(mfaTokenTTL.Seconds()),
+		}, nil
 	}
 
-	token, err := utils.GenerateJWT(user.ID, user.Email, s.cfg.JWTSecret, s.cfg.JWTIssuer, s.cfg.JWTAudience)
+	response, err := s.issueTokens(context.Background(), *user, "")
 	if err != nil {
 		log.Printf("login token generation failed for %s: %v", user.Email, err)
-		return nil, errors.New("invalid credentials")
+		return nil, ErrInvalidCredentials
 	}
 
-	return &models.LoginResponse{
-		Token: token,
-		User:  user.ToResponse(),
-	}, nil
+	return response, nil
+}
+
+// VerifyMFA completes a two-step login by exchanging the mfa pending token
+// from Login and a TOTP or recovery code for an access and refresh token.
+// Wrong codes count as failed logins of the account's address.
+func (s *AuthService) VerifyMFA(mfaToken, code string) (*models.LoginResponse, error) {
+	if mfaToken == "" || code == "" {
+		return nil, ErrMissingFields.WithMessage("mfa token and code are required")
+	}
+
+	claims, err := utils.ValidateMFAToken(mfaToken, s.cfg.JWTSecret, s.cfg.JWTIssuer, s.cfg.JWTAudience)
+	if err != nil {
+		return nil, ErrInvalidMFAToken
+	}
+
+	var (
+		response *models.LoginResponse
+		user     *models.User
+	)
+
+	err = s.transactor.Transaction(context.Background(), func(ctx context.Context) error {
+		found, err := s.users.FindByIDForUpdate(ctx, claims.UserID)
+		if err != nil {
+			return ErrInvalidMFAToken
+		}
+		user = found
+
+		if !user.IsActive || !user.TOTPEnabled || user.TokenVersion != claims.TokenVersion {
+			return ErrInvalidMFAToken
+		}
+
+		if err := s.checkLoginThrottle(ctx, user.Email); err != nil {
+			return err
+		}
+
+		ok, err := verifySecondFactor(ctx, s.cfg, s.users, s.recoveryCodes, user, code)
+		if err != nil {
+			return apperror.ErrInternal.Wrap(err)
+		}
+		if !ok {
+			return ErrInvalidMFACode
+		}
+
+		if err := s.clearLoginThrottle(ctx, user.Email); err != nil {
+			return apperror.ErrInternal.Wrap(err)
+		}
+
+		issued, err := s.issueTokens(ctx, *user, "")
+		if err != nil {
+			return apperror.ErrInternal.Wrap(err)
+		}
+		response = issued
+		return nil
+	})
+	if errors.Is(err, ErrInvalidMFACode) {
+		// Recorded after the transaction, which is rolled back, has ended.
+		return nil, s.loginFailed(user.Email, user, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// Refresh rotates a refresh token: the presented token is marked used and a
+// new one from the same family is issued alongside a fresh access token.
+// Presenting a token that was already used revokes the whole family.
+func (s *AuthService) Refresh(refreshToken string) (*models.LoginResponse, error) {
+	refreshToken = strings.TrimSpace(refreshToken)
+	if refreshToken == "" {
+		return nil, ErrMissingFields.WithMessage("refresh token is required")
+	}
+
+	tokenHash := utils.HashRefreshToken(refreshToken)
+
+	var (
+		response *models.LoginResponse
+		reused   bool
+	)
+
+	err := s.transactor.Transaction(context.Background(), func(ctx context.Context) error {
+		record, err := s.refreshTokens.FindByHashForUpdate(ctx, tokenHash)
+		if err != nil {
+			if errors.Is(err, repositories.ErrNotFound) {
+				return ErrInvalidRefreshToken
+			}
+			return apperror.ErrInternal.Wrap(err)
+		}
+
+		now := time.Now()
+
+		if record.UsedAt != nil {
+			if err := s.refreshTokens.RevokeFamily(ctx, record.FamilyID, now); err != nil {
+				return apperror.ErrInternal.Wrap(err)
+			}
+			reused = true
+			return nil
+		}
+
+		if record.RevokedAt != nil || record.ExpiresAt.Before(now) {
+			return ErrInvalidRefreshToken
+		}
+
+		user, err := s.users.FindByID(ctx, record.UserID)
+		if err != nil {
+			return ErrInvalidRefreshToken
+		}
+		if !user.IsActive {
+			return ErrInvalidRefreshToken
+		}
+
+		if err := s.refreshTokens.MarkUsed(ctx, record.ID, now); err != nil {
+			return apperror.ErrInternal.Wrap(err)
+		}
+
+		issued, err := s.issueTokens(ctx, *user, record.FamilyID)
+		if err != nil {
+			return apperror.ErrInternal.Wrap(err)
+		}
+		response = issued
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if reused {
+		log.Printf("refresh token reuse detected, token family revoked")
+		return nil, ErrInvalidRefreshToken
+	}
+
+	return response, nil
+}
+
+// Logout revokes the access token identified by jti and, when given, the
+// refresh token family it was issued with.
+func (s *AuthService) Logout(userID uint, jti string, expiresAt time.Time, refreshToken string) error {
+	if jti == "" {
+		return ErrInvalidToken
+	}
+
+	return s.transactor.Transaction(context.Background(), func(ctx context.Context) error {
+		now := time.Now()
+
+		revoked := models.RevokedToken{
+			JTI:       jti,
+			UserID:    userID,
+			ExpiresAt: expiresAt,
+		}
+		if err := s.revokedTokens.Add(ctx, &revoked); err != nil {
+			return apperror.ErrInternal.Wrap(err)
+		}
+
+		if refreshToken = strings.TrimSpace(refreshToken); refreshToken != "" {
+			record, err := s.refreshTokens.FindByHash(ctx, utils.HashRefreshToken(refreshToken))
+			if err == nil && record.UserID == userID {
+				if err := s.refreshTokens.RevokeFamily(ctx, record.FamilyID, now); err != nil {
+					return apperror.ErrInternal.Wrap(err)
+				}
+			} else if err != nil && !errors.Is(err, repositories.ErrNotFound) {
+				return apperror.ErrInternal.Wrap(err)
+			}
+		}
+
+		// Expired entries no longer need to be checked by AuthMiddleware.
+		if err := s.revokedTokens.DeleteExpired(ctx, now); err != nil {
+			return apperror.ErrInternal.Wrap(err)
+		}
+
+		return nil
+	})
+}
+
+// LogoutAll invalidates every access and refresh token issued to the user.
+func (s *AuthService) LogoutAll(userID uint) error {
+	if err := s.transactor.Transaction(context.Background(), func(ctx context.Context) error {
+		return s.revokeSessions(ctx, userID)
+	}); err != nil {
+		return apperror.ErrInternal.Wrap(err)
+	}
+	return nil
+}
+
+func (s *AuthService) VerifyEmail(token string) error {
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return ErrMissingFields.WithMessage("token is required")
+	}
+
+	rawToken, err := utils.VerifySignedToken(token, s.cfg.ResetTokenSecret)
+	if err != nil {
+		return ErrInvalidVerifyToken
+	}
+
+	tokenHash := utils.HashSignedToken(rawToken)
+
+	return s.transactor.Transaction(context.Background(), func(ctx context.Context) error {
+		record, err := s.verificationTokens.FindByHashForUpdate(ctx, tokenHash)
+		if err != nil {
+			if errors.Is(err, repositories.ErrNotFound) {
+				return ErrInvalidVerifyToken
+			}
+			return apperror.ErrInternal.Wrap(err)
+		}
+
+		if record.Used || record.ExpiresAt.Before(time.Now()) {
+			return ErrInvalidVerifyToken
+		}
+
+		user, err := s.users.FindByID(ctx, record.UserID)
+		if err != nil {
+			if errors.Is(err, repositories.ErrNotFound) {
+				return ErrInvalidVerifyToken
+			}
+			return apperror.ErrInternal.Wrap(err)
+		}
+
+		if user.EmailVerifiedAt == nil {
+			if err := s.users.MarkEmailVerified(ctx, user.ID, time.Now()); err != nil {
+				return apperror.ErrInternal.Wrap(err)
+			}
+		}
+
+		if err := s.verificationTokens.MarkUsed(ctx, record.ID); err != nil {
+			return apperror.ErrInternal.Wrap(err)
+		}
+
+		return nil
+	})
+}
+
+// ResendVerification sends a new verification link. Like ForgotPassword it
+// reports success for unknown or already verified addresses.
+func (s *AuthService) ResendVerification(email string) error {
+	email = strings.TrimSpace(email)
+	if email == "" {
+		return ErrMissingFields.WithMessage("email is required")
+	}
+
+	if !utils.ValidateEmail(email) {
+		return ErrInvalidEmail
+	}
+
+	user, err := s.users.FindByEmail(context.Background(), email)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil
+		}
+		return apperror.ErrInternal.Wrap(err)
+	}
+
+	if user.EmailVerifiedAt != nil {
+		return nil
+	}
+
+	if err := s.sendVerificationEmail(*user); err != nil {
+		return ErrEmailDelivery.Wrap(err)
+	}
+
+	return nil
 }
 
 func (s *AuthService) ForgotPassword(email string) error {
 	email = strings.TrimSpace(email)
 	if email == "" {
-		return errors.New("email is required")
+		return ErrMissingFields.WithMessage("email is required")
 	}
 
 	if !utils.ValidateEmail(email) {
-		return errors.New("invalid email format")
+		return ErrInvalidEmail
 	}
 
-	var user models.User
-	if err := database.GetDB().Where("email = ?", email).First(&user).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
+	user, err := s.users.FindByEmail(context.Background(), email)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
 
 			return nil
 		}
-		return errors.New("database error")
+		return apperror.ErrInternal.Wrap(err)
 	}
 
+	return s.SendPasswordResetEmail(*user)
+}
+
+// SendPasswordResetEmail replaces any pending reset token for the user and
+// emails a new reset link.
+func (s *AuthService) SendPasswordResetEmail(user models.User) error {
 	resetTokenValue, tokenHash, err := utils.GenerateResetToken(s.cfg.ResetTokenSecret)
 	if err != nil {
-		return errors.New("failed to generate reset token")
+		return apperror.ErrInternal.Wrap(err)
 	}
 
-	// Invalidate previous tokens for this user
-	database.GetDB().Where("user_id = ?", user.ID).Delete(&models.PasswordResetToken{})
-
 	tokenRecord := models.PasswordResetToken{
 		UserID:    user.ID,
 		TokenHash: tokenHash,
 		ExpiresAt: time.Now().Add(time.Hour),
 	}
 
-	if err := database.GetDB().Create(&tokenRecord).Error; err != nil {
-		return errors.New("failed to generate reset token")
+	// Invalidates previous tokens for this user
+	if err := s.resetTokens.Replace(context.Background(), &tokenRecord); err != nil {
+		return apperror.ErrInternal.Wrap(err)
 	}
 
 	resetLink := fmt.Sprintf("%s/reset-password?token=%s", s.cfg.FrontendURL, resetTokenValue)
 
-	emailConfig := utils.EmailConfig{
-		SMTPHost:     s.cfg.SMTPHost,
-		SMTPPort:     s.cfg.SMTPPort,
-		SMTPUsername: s.cfg.SMTPUsername,
-		SMTPPassword: s.cfg.SMTPPassword,
-		FromEmail:    s.cfg.FromEmail,
-	}
-
 	emailData := utils.EmailData{
 		To:      user.Email,
 		Subject: "Reset Your Password",
 		Body:    utils.GenerateResetPasswordEmail(resetLink),
 	}
 
-	if err := utils.SendEmail(emailConfig, emailData); err != nil {
-		return errors.New("failed to send reset email")
+	if err := utils.SendEmail(s.emailConfig(), emailData); err != nil {
+		return ErrEmailDelivery.Wrap(err)
 	}
 
 	return nil
@@ -154,60 +490,56 @@ func (s *AuthService) ForgotPassword(email string) error {
 func (s *AuthService) ResetPassword(token, newPassword string) error {
 
 	if token == "" || newPassword == "" {
-		return errors.New("token and new password are required")
+		return ErrMissingFields.WithMessage("token and new password are required")
 	}
 
 	if !utils.ValidatePassword(newPassword) {
-		return errors.New("password must include upper, lower, number, special and be at least 8 characters")
+		return ErrWeakPassword
 	}
 
 	rawToken, err := utils.VerifyResetToken(token, s.cfg.ResetTokenSecret)
 	if err != nil {
-		return errors.New("invalid or expired reset token")
+		return ErrInvalidResetToken
 	}
 
 	tokenHash := utils.HashResetToken(rawToken)
 
-	return database.GetDB().Transaction(func(tx *gorm.DB) error {
-		var resetRecord models.PasswordResetToken
-		if err := tx.
-			Clauses(clause.Locking{Strength: "UPDATE"}).
-			Where("token_hash = ?", tokenHash).
-			First(&resetRecord).Error; err != nil {
-			return errors.New("invalid or expired reset token")
+	return s.transactor.Transaction(context.Background(), func(ctx context.Context) error {
+		resetRecord, err := s.resetTokens.FindByHashForUpdate(ctx, tokenHash)
+		if err != nil {
+			if errors.Is(err, repositories.ErrNotFound) {
+				return ErrInvalidResetToken
+			}
+			return apperror.ErrInternal.Wrap(err)
 		}
 
 		if resetRecord.Used || resetRecord.ExpiresAt.Before(time.Now()) {
-			return errors.New("invalid or expired reset token")
+			return ErrInvalidResetToken
 		}
 
-		var user models.User
-		if err := tx.Where("id = ?", resetRecord.UserID).First(&user).Error; err != nil {
-			if err == gorm.ErrRecordNotFound {
-				return errors.New("invalid or expired reset token")
+		user, err := s.users.FindByID(ctx, resetRecord.UserID)
+		if err != nil {
+			if errors.Is(err, repositories.ErrNotFound) {
+				return ErrInvalidResetToken
 			}
-			return errors.New("database error")
+			return apperror.ErrInternal.Wrap(err)
 		}
 
 		hashedPassword, err := utils.HashPassword(newPassword)
 		if err != nil {
-			return errors.New("failed to hash password")
+			return apperror.ErrInternal.Wrap(err)
 		}
 
-		if err := tx.Model(&user).Update("password", hashedPassword).Error; err != nil {
-			return errors.New("failed to update password")
+		if err := s.users.UpdatePassword(ctx, user.ID, hashedPassword); err != nil {
+			return apperror.ErrInternal.Wrap(err)
 		}
 
-		if err := tx.Model(&resetRecord).Update("used", true).Error; err != nil {
-			return errors.New("failed to update reset token")
+		if err := s.resetTokens.MarkUsed(ctx, resetRecord.ID); err != nil {
+			return apperror.ErrInternal.Wrap(err)
 		}
 
-		emailConfig := utils.EmailConfig{
-			SMTPHost:     s.cfg.SMTPHost,
-			SMTPPort:     s.cfg.SMTPPort,
-			SMTPUsername: s.cfg.SMTPUsername,
-			SMTPPassword: s.cfg.SMTPPassword,
-			FromEmail:    s.cfg.FromEmail,
+		if err := s.revokeSessions(ctx, user.ID); err != nil {
+			return apperror.ErrInternal.Wrap(err)
 		}
 
 		emailData := utils.EmailData{
@@ -216,36 +548,124 @@ func (s *AuthService) ResetPassword(token, newPassword string) error {
 			Body:    utils.GeneratePasswordResetSuccessEmail(),
 		}
 
-		utils.SendEmail(emailConfig, emailData)
+		utils.SendEmail(s.emailConfig(), emailData)
 
 		return nil
 	})
 }
 
-func validateRegisterRequest(req models.CreateUserRequest) error {
-	req.Email = strings.TrimSpace(req.Email)
-	req.FirstName = strings.TrimSpace(req.FirstName)
-	req.LastName = strings.TrimSpace(req.LastName)
+// sendVerificationEmail replaces any pending verification token for the user
+// and emails a new verification link.
+func (s *AuthService) sendVerificationEmail(user models.User) error {
+	verifyTokenValue, tokenHash, err := utils.GenerateSignedToken(s.cfg.ResetTokenSecret)
+	if err != nil {
+		return err
+	}
 
-	if req.Email == "" || req.FirstName == "" || req.LastName == "" {
-		return errors.New("all fields are required")
+	tokenRecord := models.EmailVerificationToken{
+		UserID:    user.ID,
+		TokenHash: tokenHash,
+		ExpiresAt: time.Now().Add(24 * time.Hour),
 	}
-	if !utils.ValidateEmail(req.Email) {
-		return errors.New("invalid email format")
+
+	if err := s.verificationTokens.Replace(context.Background(), &tokenRecord); err != nil {
+		return err
 	}
-	if !utils.ValidatePassword(req.Password) {
-		return errors.New("password must include upper, lower, number, special and be at least 8 characters")
+
+	verifyLink := fmt.Sprintf("%s/verify-email?token=%s", s.cfg.FrontendURL, verifyTokenValue)
+
+	emailData := utils.EmailData{
+		To:      user.Email,
+		Subject: "Verify Your Email",
+		Body:    utils.GenerateVerifyEmail(verifyLink),
+	}
+
+	return utils.SendEmail(s.emailConfig(), emailData)
+}
+
+func (s *AuthService) emailConfig() utils.EmailConfig {
+	return utils.EmailConfig{
+		SMTPHost:     s.cfg.SMTPHost,
+		SMTPPort:     s.cfg.SMTPPort,
+		SMTPUsername: s.cfg.SMTPUsername,
+		SMTPPassword: s.cfg.SMTPPassword,
+		FromEmail:    s.cfg.FromEmail,
 	}
-	return nil
 }
 
-func validateLoginRequest(req models.LoginRequest) error {
-	req.Email = strings.TrimSpace(req.Email)
-	if req.Email == "" || req.Password == "" {
-		return errors.New("email and password are required")
+// issueTokens creates an access token and a refresh token for the user. An
+// empty familyID starts a new refresh token family.
+func (s *AuthService) issueTokens(ctx context.Context, user models.User, familyID string) (*models.LoginResponse, error) {
+	accessToken, err := utils.GenerateJWT(user.ID, user.Email, user.TokenVersion, s.cfg.JWTSecret, s.cfg.JWTIssuer, s.cfg.JWTAudience, s.cfg.AccessTokenTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	if familyID == "" {
+		if familyID, err = utils.GenerateRandomToken(16); err != nil {
+			return nil, err
+		}
 	}
-	if !utils.ValidateEmail(req.Email) {
-		return errors.New("invalid email format")
+
+	refreshToken, tokenHash, err := utils.GenerateRefreshToken()
+	if err != nil {
+		return nil, err
 	}
-	return nil
+
+	record := models.RefreshToken{
+		UserID:    user.ID,
+		FamilyID:  familyID,
+		TokenHash: tokenHash,
+		ExpiresAt: time.Now().Add(s.cfg.RefreshTokenTTL),
+	}
+	if err := s.refreshTokens.Create(ctx, &record); err != nil {
+		return nil, err
+	}
+
+	userResponse := user.ToResponse()
+	return &models.LoginResponse{
+		Token:        accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(s.cfg.AccessTokenTTL.Seconds()),
+		User:         &userResponse,
+	}, nil
+}
+
+// SessionUser returns the user an access token was issued to, or nil when
+// the token has been invalidated since: the user is gone, their sessions
+// were revoked or the token was logged out.
+func (s *AuthService) SessionUser(claims *utils.Claims) (*models.User, error) {
+	ctx := context.Background()
+
+	user, err := s.users.FindByID(ctx, claims.UserID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	if claims.TokenVersion != user.TokenVersion {
+		return nil, nil
+	}
+
+	revoked, err := s.revokedTokens.Exists(ctx, claims.ID)
+	if err != nil {
+		return nil, err
+	}
+	if revoked {
+		return nil, nil
+	}
+
+	return user, nil
+}
+
+// revokeSessions bumps the user's token version so AuthMiddleware rejects
+// every outstanding access token, and revokes all of their refresh tokens.
+// It joins the transaction carried by ctx.
+func (s *AuthService) revokeSessions(ctx context.Context, userID uint) error {
+	if err := s.users.IncrementTokenVersion(ctx, userID); err != nil {
+		return err
+	}
+	return s.refreshTokens.RevokeAllForUser(ctx, userID, time.Now())
 }
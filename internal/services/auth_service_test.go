@@ -0,0 +1,392 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go-fiber-boilerplate/config"
+	"go-fiber-boilerplate/internal/models"
+	"go-fiber-boilerplate/internal/repositories/memory"
+	"go-fiber-boilerplate/utils"
+
+	"gorm.io/gorm"
+)
+
+const testPassword = "Passw0rd!x"
+
+func testConfig() *config.Config {
+	return &config.Config{
+		JWTSecret:             "test-jwt-secret",
+		JWTIssuer:             "test",
+		JWTAudience:           "test",
+		AccessTokenTTL:        15 * time.Minute,
+		RefreshTokenTTL:       time.Hour,
+		ResetTokenSecret:      "test-reset-secret",
+		MFAEncryptionKey:      "test-mfa-key",
+		LoginLockoutThreshold: 5,
+		LoginLockoutDuration:  time.Hour,
+		LoginBackoffBase:      time.Minute,
+	}
+}
+
+func newTestAuthService(t *testing.T) (*AuthService, *memory.Repositories) {
+	t.Helper()
+
+	repos := memory.New()
+	svc := NewAuthService(testConfig(), AuthRepositories{
+		Transactor:         repos.Transactor,
+		Users:              repos.Users,
+		ResetTokens:        repos.ResetTokens,
+		RefreshTokens:      repos.RefreshTokens,
+		RevokedTokens:      repos.RevokedTokens,
+		VerificationTokens: repos.VerificationTokens,
+		UnlockTokens:       repos.UnlockTokens,
+		LoginThrottles:     repos.LoginThrottles,
+		RecoveryCodes:      repos.RecoveryCodes,
+	})
+	t.Cleanup(svc.Wait)
+	return svc, repos
+}
+
+func register(t *testing.T, svc *AuthService, email string) models.UserResponse {
+	t.Helper()
+
+	resp, err := svc.Register(models.CreateUserRequest{
+		Email:     email,
+		Password:  testPassword,
+		FirstName: "Test",
+		LastName:  "User",
+	})
+	if err != nil {
+		t.Fatalf("Register(%q) error = %v", email, err)
+	}
+	return resp.User
+}
+
+func login(t *testing.T, svc *AuthService, email, password string) *models.LoginResponse {
+	t.Helper()
+
+	resp, err := svc.Login(models.LoginRequest{Email: email, Password: password})
+	if err != nil {
+		t.Fatalf("Login(%q) error = %v", email, err)
+	}
+	return resp
+}
+
+func TestRegister(t *testing.T) {
+	svc, repos := newTestAuthService(t)
+
+	user := register(t, svc, "alice@example.com")
+
+	stored, err := repos.Users.FindByID(context.Background(), user.ID)
+	if err != nil {
+		t.Fatalf("registered user not stored: %v", err)
+	}
+	if stored.Password == testPassword || !utils.CheckPassword(testPassword, stored.Password) {
+		t.Error("password is not stored as a matching hash")
+	}
+	if len(stored.Roles) != 1 || stored.Roles[0].Name != models.RoleUser {
+		t.Errorf("roles = %v, want [%s]", stored.Roles, models.RoleUser)
+	}
+
+	_, err = svc.Register(models.CreateUserRequest{Email: "alice@example.com", Password: testPassword})
+	if !errors.Is(err, ErrEmailTaken) {
+		t.Errorf("second Register() error = %v, want ErrEmailTaken", err)
+	}
+
+	// A deleted account keeps its email under the unique index.
+	stored.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+	repos.Users.Put(*stored)
+	_, err = svc.Register(models.CreateUserRequest{Email: "alice@example.com", Password: testPassword})
+	if !errors.Is(err, ErrEmailTaken) {
+		t.Errorf("Register() with a deleted user's email error = %v, want ErrEmailTaken", err)
+	}
+}
+
+func TestLogin(t *testing.T) {
+	svc, repos := newTestAuthService(t)
+	user := register(t, svc, "bob@example.com")
+
+	resp := login(t, svc, "bob@example.com", testPassword)
+	if resp.Token == "" || resp.RefreshToken == "" {
+		t.Fatalf("Login() = %+v, want an access and a refresh token", resp)
+	}
+	claims, err := utils.ValidateJWT(resp.Token, svc.cfg.JWTSecret, svc.cfg.JWTIssuer, svc.cfg.JWTAudience)
+	if err != nil || claims.UserID != user.ID {
+		t.Fatalf("access token claims = %+v, %v; want user %d", claims, err, user.ID)
+	}
+
+	for _, tt := range []struct {
+		name     string
+		email    string
+		password string
+	}{
+		{"wrong password", "bob@example.com", "Wr0ngPass!"},
+		{"unknown email", "nobody@example.com", testPassword},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := svc.Login(models.LoginRequest{Email: tt.email, Password: tt.password})
+			if !errors.Is(err, ErrInvalidCredentials) {
+				t.Errorf("Login() error = %v, want ErrInvalidCredentials", err)
+			}
+		})
+	}
+
+	t.Run("inactive account", func(t *testing.T) {
+		stored, _ := repos.Users.FindByID(context.Background(), user.ID)
+		stored.IsActive = false
+		repos.Users.Put(*stored)
+		defer func() {
+			stored.IsActive = true
+			repos.Users.Put(*stored)
+		}()
+
+		_, err := svc.Login(models.LoginRequest{Email: "bob@example.com", Password: testPassword})
+		if !errors.Is(err, ErrInvalidCredentials) {
+			t.Errorf("Login() error = %v, want ErrInvalidCredentials", err)
+		}
+	})
+}
+
+func TestLoginThrottle(t *testing.T) {
+	svc, _ := newTestAuthService(t)
+	register(t, svc, "carol@example.com")
+
+	attempt := func() error {
+		_, err := svc.Login(models.LoginRequest{Email: "Carol@example.com", Password: "Wr0ngPass!"})
+		return err
+	}
+
+	// The free attempts and the one that starts the backoff are rejected as
+	// invalid, after which the address has to wait.
+	for i := 0; i <= loginFreeAttempts; i++ {
+		if err := attempt(); !errors.Is(err, ErrInvalidCredentials) {
+			t.Fatalf("attempt %d error = %v, want ErrInvalidCredentials", i+1, err)
+		}
+	}
+
+	var throttled *ThrottledError
+	if err := attempt(); !errors.As(err, &throttled) || throttled.RetryAfter <= 0 {
+		t.Fatalf("attempt after backoff error = %v, want ThrottledError", err)
+	}
+	_, err := svc.Login(models.LoginRequest{Email: "carol@example.com", Password: testPassword})
+	if !errors.Is(err, ErrLoginThrottled) {
+		t.Errorf("correct password while throttled error = %v, want ErrLoginThrottled", err)
+	}
+}
+
+func TestPurgeLoginThrottles(t *testing.T) {
+	svc, repos := newTestAuthService(t)
+	ctx := context.Background()
+	now := time.Now()
+	lockedUntil := now.Add(time.Hour)
+
+	for _, throttle := range []models.LoginThrottle{
+		{EmailHash: loginEmailHash("quiet@example.com"), FailedAttempts: 2, LastFailedAt: now.Add(-2 * loginFailureWindow)},
+		{EmailHash: loginEmailHash("recent@example.com"), FailedAttempts: 2, LastFailedAt: now},
+		{EmailHash: loginEmailHash("locked@example.com"), FailedAttempts: 9, LastFailedAt: now.Add(-2 * loginFailureWindow), LockedUntil: &lockedUntil},
+	} {
+		if err := repos.LoginThrottles.Save(ctx, &throttle); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	purged, err := svc.PurgeLoginThrottles()
+	if err != nil || purged != 1 {
+		t.Fatalf("PurgeLoginThrottles() = %d, %v; want 1", purged, err)
+	}
+	if _, err := repos.LoginThrottles.Find(ctx, loginEmailHash("quiet@example.com")); err == nil {
+		t.Error("stale throttle was kept")
+	}
+	for _, email := range []string{"recent@example.com", "locked@example.com"} {
+		if _, err := repos.LoginThrottles.Find(ctx, loginEmailHash(email)); err != nil {
+			t.Errorf("throttle of %s was purged: %v", email, err)
+		}
+	}
+}
+
+// enableMFA turns on MFA for the user with an encrypted secret and returns
+// its recovery codes.
+func enableMFA(t *testing.T, svc *AuthService, repos *memory.Repositories, userID uint) []string {
+	t.Helper()
+	ctx := context.Background()
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	encrypted, err := utils.EncryptTOTPSecret(secret, svc.cfg.MFAEncryptionKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	codes, err := utils.GenerateRecoveryCodes()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stored, err := repos.Users.FindByID(ctx, userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stored.TOTPSecret = encrypted
+	stored.TOTPEnabled = true
+	repos.Users.Put(*stored)
+	if err := repos.RecoveryCodes.Replace(ctx, userID, hashRecoveryCodes(codes)); err != nil {
+		t.Fatal(err)
+	}
+	return codes
+}
+
+func TestVerifyMFA(t *testing.T) {
+	svc, repos := newTestAuthService(t)
+	user := register(t, svc, "grace@example.com")
+	codes := enableMFA(t, svc, repos, user.ID)
+
+	pending := login(t, svc, "grace@example.com", testPassword)
+	if !pending.MFARequired || pending.MFAToken == "" || pending.Token != "" {
+		t.Fatalf("Login() = %+v, want only an mfa token", pending)
+	}
+
+	resp, err := svc.VerifyMFA(pending.MFAToken, codes[0])
+	if err != nil || resp.Token == "" || resp.RefreshToken == "" {
+		t.Fatalf("VerifyMFA(recovery code) = %+v, %v; want tokens", resp, err)
+	}
+	if _, err := svc.VerifyMFA(pending.MFAToken, codes[0]); !errors.Is(err, ErrInvalidMFACode) {
+		t.Errorf("VerifyMFA(used recovery code) error = %v, want ErrInvalidMFACode", err)
+	}
+	if _, err := svc.VerifyMFA("not-a-token", codes[1]); !errors.Is(err, ErrInvalidMFAToken) {
+		t.Errorf("VerifyMFA(invalid token) error = %v, want ErrInvalidMFAToken", err)
+	}
+}
+
+func TestVerifyMFAThrottle(t *testing.T) {
+	svc, repos := newTestAuthService(t)
+	user := register(t, svc, "heidi@example.com")
+	codes := enableMFA(t, svc, repos, user.ID)
+
+	// Logging in again with the password does not reset the count of wrong
+	// codes.
+	for i := 0; i <= loginFreeAttempts; i++ {
+		pending := login(t, svc, "heidi@example.com", testPassword)
+		if _, err := svc.VerifyMFA(pending.MFAToken, "wrong-code"); !errors.Is(err, ErrInvalidMFACode) {
+			t.Fatalf("attempt %d error = %v, want ErrInvalidMFACode", i+1, err)
+		}
+	}
+
+	if _, err := svc.Login(models.LoginRequest{Email: "heidi@example.com", Password: testPassword}); !errors.Is(err, ErrLoginThrottled) {
+		t.Errorf("Login() after wrong codes error = %v, want ErrLoginThrottled", err)
+	}
+
+	// A pending token issued before the backoff cannot be used to keep
+	// guessing either.
+	stored, err := repos.Users.FindByID(context.Background(), user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mfaToken, err := utils.GenerateMFAToken(user.ID, user.Email, stored.TokenVersion, svc.cfg.JWTSecret, svc.cfg.JWTIssuer, svc.cfg.JWTAudience, mfaTokenTTL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var throttled *ThrottledError
+	if _, err := svc.VerifyMFA(mfaToken, codes[0]); !errors.As(err, &throttled) {
+		t.Errorf("VerifyMFA() while throttled error = %v, want ThrottledError", err)
+	}
+}
+
+func TestRefresh(t *testing.T) {
+	svc, _ := newTestAuthService(t)
+	register(t, svc, "dave@example.com")
+	first := login(t, svc, "dave@example.com", testPassword)
+
+	second, err := svc.Refresh(first.RefreshToken)
+	if err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+	if second.RefreshToken == first.RefreshToken || second.Token == "" {
+		t.Fatalf("Refresh() did not rotate the tokens: %+v", second)
+	}
+
+	// Presenting the used token again revokes the whole family, including
+	// the token it was rotated into.
+	if _, err := svc.Refresh(first.RefreshToken); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("reused Refresh() error = %v, want ErrInvalidRefreshToken", err)
+	}
+	if _, err := svc.Refresh(second.RefreshToken); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("Refresh() after reuse error = %v, want ErrInvalidRefreshToken", err)
+	}
+
+	if _, err := svc.Refresh("not-a-token"); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("Refresh(unknown) error = %v, want ErrInvalidRefreshToken", err)
+	}
+}
+
+func TestLogout(t *testing.T) {
+	svc, _ := newTestAuthService(t)
+	register(t, svc, "erin@example.com")
+	resp := login(t, svc, "erin@example.com", testPassword)
+
+	claims, err := utils.ValidateJWT(resp.Token, svc.cfg.JWTSecret, svc.cfg.JWTIssuer, svc.cfg.JWTAudience)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user, err := svc.SessionUser(claims); err != nil || user == nil {
+		t.Fatalf("SessionUser() before logout = %v, %v; want the user", user, err)
+	}
+
+	if err := svc.Logout(claims.UserID, claims.ID, claims.ExpiresAt.Time, resp.RefreshToken); err != nil {
+		t.Fatalf("Logout() error = %v", err)
+	}
+
+	if user, err := svc.SessionUser(claims); err != nil || user != nil {
+		t.Errorf("SessionUser() after logout = %v, %v; want nil", user, err)
+	}
+	if _, err := svc.Refresh(resp.RefreshToken); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("Refresh() after logout error = %v, want ErrInvalidRefreshToken", err)
+	}
+}
+
+func TestResetPassword(t *testing.T) {
+	svc, repos := newTestAuthService(t)
+	user := register(t, svc, "frank@example.com")
+	session := login(t, svc, "frank@example.com", testPassword)
+
+	token, tokenHash, err := utils.GenerateResetToken(svc.cfg.ResetTokenSecret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := repos.ResetTokens.Replace(context.Background(), &models.PasswordResetToken{
+		UserID:    user.ID,
+		TokenHash: tokenHash,
+		ExpiresAt: time.Now().Add(time.Hour),
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := svc.ResetPassword(token, "weak"); !errors.Is(err, ErrWeakPassword) {
+		t.Errorf("ResetPassword(weak) error = %v, want ErrWeakPassword", err)
+	}
+
+	const newPassword = "N3wPassw0rd!"
+	if err := svc.ResetPassword(token, newPassword); err != nil {
+		t.Fatalf("ResetPassword() error = %v", err)
+	}
+
+	if _, err := svc.Login(models.LoginRequest{Email: "frank@example.com", Password: testPassword}); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("Login(old password) error = %v, want ErrInvalidCredentials", err)
+	}
+	login(t, svc, "frank@example.com", newPassword)
+
+	// Resetting signs the user out everywhere.
+	if _, err := svc.Refresh(session.RefreshToken); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("Refresh() of a session from before the reset error = %v, want ErrInvalidRefreshToken", err)
+	}
+
+	if err := svc.ResetPassword(token, "An0therPass!"); !errors.Is(err, ErrInvalidResetToken) {
+		t.Errorf("second ResetPassword() error = %v, want ErrInvalidResetToken", err)
+	}
+	if err := svc.ResetPassword("forged.token", newPassword); !errors.Is(err, ErrInvalidResetToken) {
+		t.Errorf("ResetPassword(forged) error = %v, want ErrInvalidResetToken", err)
+	}
+}
@@ -0,0 +1,52 @@
+package services
+
+import (
+	"net/http"
+
+	"go-fiber-boilerplate/pkg/apperror"
+)
+
+// Domain errors returned by the services. Controllers return them unchanged
+// and middlewares.ErrorHandler renders their code, status and message.
+var (
+	ErrEmailTaken           = apperror.New("email_taken", http.StatusConflict, "user with this email already exists")
+	ErrMissingFields        = apperror.New("missing_fields", http.StatusBadRequest, "all fields are required")
+	ErrInvalidEmail         = apperror.New("invalid_email", http.StatusBadRequest, "invalid email format")
+	ErrWeakPassword         = apperror.New("weak_password", http.StatusBadRequest, "password must include upper, lower, number, special and be at least 8 characters")
+	ErrInvalidCredentials   = apperror.New("invalid_credentials", http.StatusUnauthorized, "invalid credentials")
+	ErrLoginThrottled       = apperror.New("login_throttled", http.StatusTooManyRequests, "too many failed login attempts, try again later")
+	ErrInvalidUnlockToken   = apperror.New("invalid_unlock_token", http.StatusBadRequest, "invalid or expired unlock token")
+	ErrEmailNotVerified     = apperror.New("email_not_verified", http.StatusForbidden, "email address has not been verified")
+	ErrInvalidRefreshToken  = apperror.New("invalid_refresh_token", http.StatusUnauthorized, "invalid refresh token")
+	ErrInvalidToken         = apperror.New("invalid_token", http.StatusUnauthorized, "invalid token")
+	ErrInvalidResetToken    = apperror.New("invalid_reset_token", http.StatusBadRequest, "invalid or expired reset token")
+	ErrInvalidVerifyToken   = apperror.New("invalid_verification_token", http.StatusBadRequest, "invalid or expired verification token")
+	ErrEmailDelivery        = apperror.New("email_delivery_failed", http.StatusInternalServerError, "unable to send email")
+	ErrInvalidMFAToken      = apperror.New("invalid_mfa_token", http.StatusUnauthorized, "invalid or expired mfa token")
+	ErrInvalidMFACode       = apperror.New("invalid_mfa_code", http.StatusUnauthorized, "invalid mfa code")
+	ErrMFAAlreadyEnabled    = apperror.New("mfa_already_enabled", http.StatusConflict, "mfa already enabled")
+	ErrMFANotEnabled        = apperror.New("mfa_not_enabled", http.StatusBadRequest, "mfa not enabled")
+	ErrMFAEnrollmentMissing = apperror.New("mfa_enrollment_not_started", http.StatusBadRequest, "mfa enrollment not started")
+	ErrUserNotFound         = apperror.New("user_not_found", http.StatusNotFound, "user not found")
+	ErrUserDeleted          = apperror.New("user_deleted", http.StatusBadRequest, "user is deleted")
+	ErrSelfDeactivation     = apperror.New("self_deactivation", http.StatusBadRequest, "cannot deactivate or delete your own account")
+	ErrRoleRequired         = apperror.New("role_required", http.StatusBadRequest, "at least one role is required")
+	ErrUnknownRole          = apperror.New("unknown_role", http.StatusBadRequest, "unknown role")
+	ErrSampleNotFound       = apperror.New("sample_not_found", http.StatusNotFound, "sample not found")
+	ErrSampleForbidden      = apperror.New("sample_forbidden", http.StatusForbidden, "you don't have permission to modify this sample")
+	ErrTitleTaken           = apperror.New("title_taken", http.StatusConflict, "title already exists")
+	ErrInvalidImage         = apperror.New("invalid_image", http.StatusBadRequest, "file must be a JPEG or PNG image (jpg, jpeg, png)")
+	ErrImageTooLarge        = apperror.New("image_too_large", http.StatusBadRequest, "file size exceeds 5MB limit")
+	ErrImageDimensions      = apperror.New("image_dimensions_too_large", http.StatusRequestEntityTooLarge, "image dimensions exceed the 40 megapixel limit")
+	ErrImageUpload          = apperror.New("image_upload_failed", http.StatusBadGateway, "failed to upload image")
+	ErrUploadNotFound       = apperror.New("upload_not_found", http.StatusNotFound, "upload not found")
+	ErrUploadLengthRequired = apperror.New("upload_length_required", http.StatusBadRequest, "a positive Upload-Length is required")
+	ErrUploadTooLarge       = apperror.New("upload_too_large", http.StatusRequestEntityTooLarge, "upload exceeds the maximum size")
+	ErrUploadExceedsLength  = apperror.New("upload_exceeds_length", http.StatusRequestEntityTooLarge, "chunk exceeds the declared upload length")
+	ErrUploadOffsetMismatch = apperror.New("upload_offset_mismatch", http.StatusConflict, "Upload-Offset does not match the current offset")
+	ErrUploadIncomplete     = apperror.New("upload_incomplete", http.StatusConflict, "upload is not complete")
+	ErrImageRequired        = apperror.New("image_required", http.StatusBadRequest, "an image file or upload_id is required")
+	ErrSampleImageNotFound  = apperror.New("sample_image_not_found", http.StatusNotFound, "sample image not found")
+	ErrGalleryFull          = apperror.New("gallery_full", http.StatusConflict, "sample gallery is full")
+	ErrInvalidImageOrder    = apperror.New("invalid_image_order", http.StatusBadRequest, "image_ids must list every image of the sample exactly once")
+)
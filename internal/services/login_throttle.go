@@ -0,0 +1,276 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"go-fiber-boilerplate/internal/models"
+	"go-fiber-boilerplate/internal/repositories"
+	"go-fiber-boilerplate/pkg/apperror"
+	"go-fiber-boilerplate/utils"
+)
+
+// Failed-login throttling of AuthService. Failures are counted per email
+// address in login_throttles, for registered and unknown addresses alike,
+// and Login treats both the same way so responses and timings do not reveal
+// whether an account exists. The first few failures are free, later ones
+// make the address wait LOGIN_BACKOFF_BASE doubling per failure, and
+// LOGIN_LOCKOUT_THRESHOLD failures lock it for LOGIN_LOCKOUT_DURATION.
+
+const (
+	loginFreeAttempts  = 3
+	loginFailureWindow = 24 * time.Hour
+	unlockTokenTTL     = 24 * time.Hour
+)
+
+// ThrottledError is returned by Login, VerifyMFA and MFAService.Disable
+// while an address is backing off or locked. It unwraps to
+// ErrLoginThrottled.
+type ThrottledError struct {
+	RetryAfter time.Duration
+}
+
+func (e *ThrottledError) Error() string {
+	return ErrLoginThrottled.Error()
+}
+
+func (e *ThrottledError) Unwrap() error {
+	return ErrLoginThrottled
+}
+
+// dummyPasswordHash is compared against for unknown addresses so a login for
+// them costs as much as one for a registered account.
+var dummyPasswordHash = sync.OnceValue(func() string {
+	hash, err := utils.HashPassword("dummy-password-for-timing")
+	if err != nil {
+		log.Printf("warning: failed to hash dummy password: %v", err)
+	}
+	return hash
+})
+
+// UnlockAccount clears the lockout of the account the unlock link was sent
+// to.
+func (s *AuthService) UnlockAccount(token string) error {
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return ErrMissingFields.WithMessage("token is required")
+	}
+
+	rawToken, err := utils.VerifySignedToken(token, s.cfg.ResetTokenSecret)
+	if err != nil {
+		return ErrInvalidUnlockToken
+	}
+
+	tokenHash := utils.HashSignedToken(rawToken)
+
+	return s.transactor.Transaction(context.Background(), func(ctx context.Context) error {
+		record, err := s.unlockTokens.FindByHashForUpdate(ctx, tokenHash)
+		if err != nil {
+			if errors.Is(err, repositories.ErrNotFound) {
+				return ErrInvalidUnlockToken
+			}
+			return apperror.ErrInternal.Wrap(err)
+		}
+
+		if record.Used || record.ExpiresAt.Before(time.Now()) {
+			return ErrInvalidUnlockToken
+		}
+
+		user, err := s.users.FindByID(ctx, record.UserID)
+		if err != nil {
+			if errors.Is(err, repositories.ErrNotFound) {
+				return ErrInvalidUnlockToken
+			}
+			return apperror.ErrInternal.Wrap(err)
+		}
+
+		if err := s.clearLoginThrottle(ctx, user.Email); err != nil {
+			return apperror.ErrInternal.Wrap(err)
+		}
+
+		if err := s.unlockTokens.MarkUsed(ctx, record.ID); err != nil {
+			return apperror.ErrInternal.Wrap(err)
+		}
+
+		return nil
+	})
+}
+
+// clearLockout lifts a lockout of the user on behalf of an admin, also
+// invalidating the unlock link they were sent. It joins the transaction
+// carried by ctx.
+func (s *AuthService) clearLockout(ctx context.Context, user models.User) error {
+	if err := s.unlockTokens.DeleteForUser(ctx, user.ID); err != nil {
+		return err
+	}
+	return s.clearLoginThrottle(ctx, user.Email)
+}
+
+// loginFailed records a failed login, a wrong password or second factor, and
+// returns err. When the failure locks a registered account its owner is
+// emailed an unlock link, in the background so the response takes as long as
+// for an unknown address.
+func (s *AuthService) loginFailed(email string, user *models.User, err error) error {
+	locked, recordErr := s.recordLoginFailure(email)
+	if recordErr != nil {
+		log.Printf("warning: failed to record login failure: %v", recordErr)
+		return err
+	}
+
+	if locked && user != nil {
+		s.background.Add(1)
+		go func(user models.User) {
+			defer s.background.Done()
+			if err := s.sendUnlockEmail(user); err != nil {
+				log.Printf("failed to send unlock email to %s: %v", user.Email, err)
+			}
+		}(*user)
+	}
+	return err
+}
+
+// checkLoginThrottle returns a ThrottledError while the address may not
+// attempt a login. It joins the transaction carried by ctx.
+func (s *AuthService) checkLoginThrottle(ctx context.Context, email string) error {
+	throttle, err := s.throttles.Find(ctx, loginEmailHash(email))
+	if errors.Is(err, repositories.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return apperror.ErrInternal.Wrap(err)
+	}
+
+	if throttle.LockedUntil != nil {
+		if wait := time.Until(*throttle.LockedUntil); wait > 0 {
+			return &ThrottledError{RetryAfter: wait}
+		}
+	}
+	return nil
+}
+
+// recordLoginFailure counts a failed login for the address and reports
+// whether it is now locked out. Failures older than loginFailureWindow are
+// forgotten.
+func (s *AuthService) recordLoginFailure(email string) (bool, error) {
+	emailHash := loginEmailHash(email)
+	locked := false
+
+	err := s.transactor.Transaction(context.Background(), func(ctx context.Context) error {
+		throttle, err := s.throttles.FindOrCreateForUpdate(ctx, emailHash)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		if now.Sub(throttle.LastFailedAt) > loginFailureWindow {
+			throttle.FailedAttempts = 0
+		}
+		throttle.FailedAttempts++
+		throttle.LastFailedAt = now
+		throttle.LockedUntil = nil
+
+		locked = throttle.FailedAttempts >= s.cfg.LoginLockoutThreshold
+		if delay := s.loginDelay(throttle.FailedAttempts); delay > 0 {
+			until := now.Add(delay)
+			throttle.LockedUntil = &until
+		}
+
+		return s.throttles.Save(ctx, throttle)
+	})
+	return locked, err
+}
+
+// loginDelay is how long an address must wait after its failures-th
+// consecutive failure.
+func (s *AuthService) loginDelay(failures int) time.Duration {
+	if failures >= s.cfg.LoginLockoutThreshold {
+		return s.cfg.LoginLockoutDuration
+	}
+	if failures <= loginFreeAttempts {
+		return 0
+	}
+
+	delay := s.cfg.LoginBackoffBase
+	for i := loginFreeAttempts + 1; i < failures && delay < s.cfg.LoginLockoutDuration; i++ {
+		delay *= 2
+	}
+	return min(delay, s.cfg.LoginLockoutDuration)
+}
+
+// clearLoginThrottle forgets the failures of an address.
+func (s *AuthService) clearLoginThrottle(ctx context.Context, email string) error {
+	return s.throttles.Delete(ctx, loginEmailHash(email))
+}
+
+// PurgeLoginThrottles removes the throttles of addresses that have gone
+// quiet, whose failures would be forgotten anyway, and returns how many.
+func (s *AuthService) PurgeLoginThrottles() (int64, error) {
+	now := time.Now()
+	return s.throttles.DeleteStale(context.Background(), now.Add(-loginFailureWindow), now)
+}
+
+// Start purges stale login throttles every interval until ctx is cancelled,
+// so the table does not grow with every address that ever failed a login.
+func (s *AuthService) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			purged, err := s.PurgeLoginThrottles()
+			if err != nil {
+				log.Printf("failed to purge login throttles: %v", err)
+				continue
+			}
+			if purged > 0 {
+				log.Printf("purged %d stale login throttles", purged)
+			}
+		}
+	}
+}
+
+// sendUnlockEmail replaces any pending unlock token for the user and emails a
+// link that lifts the lockout.
+func (s *AuthService) sendUnlockEmail(user models.User) error {
+	unlockTokenValue, tokenHash, err := utils.GenerateSignedToken(s.cfg.ResetTokenSecret)
+	if err != nil {
+		return err
+	}
+
+	tokenRecord := models.AccountUnlockToken{
+		UserID:    user.ID,
+		TokenHash: tokenHash,
+		ExpiresAt: time.Now().Add(unlockTokenTTL),
+	}
+
+	if err := s.unlockTokens.Replace(context.Background(), &tokenRecord); err != nil {
+		return err
+	}
+
+	unlockLink := fmt.Sprintf("%s/unlock-account?token=%s", s.cfg.FrontendURL, unlockTokenValue)
+
+	emailData := utils.EmailData{
+		To:      user.Email,
+		Subject: "Your Account Has Been Locked",
+		Body:    utils.GenerateUnlockAccountEmail(unlockLink),
+	}
+
+	return utils.SendEmail(s.emailConfig(), emailData)
+}
+
+// loginEmailHash identifies an address in login_throttles without storing
+// it, folding case so variants of one address share a throttle.
+func loginEmailHash(email string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(strings.TrimSpace(email))))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,217 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"go-fiber-boilerplate/config"
+	"go-fiber-boilerplate/internal/models"
+	"go-fiber-boilerplate/internal/repositories"
+	"go-fiber-boilerplate/pkg/apperror"
+	"go-fiber-boilerplate/utils"
+)
+
+// MFAService manages TOTP enrolment. Wrong codes count towards the same
+// login throttle as VerifyMFA, kept by authService.
+type MFAService struct {
+	cfg           *config.Config
+	transactor    repositories.Transactor
+	users         repositories.UserRepository
+	recoveryCodes repositories.RecoveryCodeRepository
+	authService   *AuthService
+}
+
+func NewMFAService(
+	cfg *config.Config,
+	transactor repositories.Transactor,
+	users repositories.UserRepository,
+	recoveryCodes repositories.RecoveryCodeRepository,
+	authService *AuthService,
+) *MFAService {
+	return &MFAService{
+		cfg:           cfg,
+		transactor:    transactor,
+		users:         users,
+		recoveryCodes: recoveryCodes,
+		authService:   authService,
+	}
+}
+
+// Enroll generates a new TOTP secret for the user. The secret stays inactive
+// until it is confirmed with a valid code.
+func (s *MFAService) Enroll(userID uint) (*models.MFAEnrollResponse, error) {
+	ctx := context.Background()
+
+	user, err := s.findUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.TOTPEnabled {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		return nil, apperror.ErrInternal.Wrap(err)
+	}
+
+	encrypted, err := utils.EncryptTOTPSecret(secret, s.cfg.MFAEncryptionKey)
+	if err != nil {
+		return nil, apperror.ErrInternal.Wrap(err)
+	}
+
+	if err := s.users.SetTOTPSecret(ctx, user.ID, encrypted); err != nil {
+		return nil, apperror.ErrInternal.Wrap(err)
+	}
+
+	return &models.MFAEnrollResponse{
+		Secret:     secret,
+		OTPAuthURL: utils.TOTPURI(secret, s.cfg.MFAIssuer, user.Email),
+	}, nil
+}
+
+// Confirm activates the pending TOTP secret and returns a fresh set of
+// recovery codes. The plain codes are only ever returned here.
+func (s *MFAService) Confirm(userID uint, code string) (*models.MFARecoveryCodesResponse, error) {
+	user, err := s.findUser(context.Background(), userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.TOTPEnabled {
+		return nil, ErrMFAAlreadyEnabled
+	}
+	if user.TOTPSecret == "" {
+		return nil, ErrMFAEnrollmentMissing
+	}
+
+	secret, err := utils.DecryptTOTPSecret(user.TOTPSecret, s.cfg.MFAEncryptionKey)
+	if err != nil {
+		return nil, apperror.ErrInternal.Wrap(err)
+	}
+
+	step, ok := utils.ValidateTOTP(secret, code, time.Now(), user.TOTPLastStep)
+	if !ok {
+		return nil, ErrInvalidMFACode.WithStatus(http.StatusBadRequest)
+	}
+
+	codes, err := utils.GenerateRecoveryCodes()
+	if err != nil {
+		return nil, apperror.ErrInternal.Wrap(err)
+	}
+
+	err = s.transactor.Transaction(context.Background(), func(ctx context.Context) error {
+		if err := s.users.EnableTOTP(ctx, user.ID, step); err != nil {
+			return err
+		}
+		return s.recoveryCodes.Replace(ctx, user.ID, hashRecoveryCodes(codes))
+	})
+	if err != nil {
+		return nil, apperror.ErrInternal.Wrap(err)
+	}
+
+	return &models.MFARecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// Disable turns MFA off after re-checking the password and a second factor.
+func (s *MFAService) Disable(userID uint, password, code string) error {
+	if password == "" || code == "" {
+		return ErrMissingFields.WithMessage("password and code are required")
+	}
+
+	var user *models.User
+	err := s.transactor.Transaction(context.Background(), func(ctx context.Context) error {
+		// Locked so a concurrent login cannot consume the same code.
+		found, err := s.users.FindByIDForUpdate(ctx, userID)
+		if err != nil {
+			if errors.Is(err, repositories.ErrNotFound) {
+				return ErrUserNotFound
+			}
+			return apperror.ErrInternal.Wrap(err)
+		}
+		user = found
+
+		if !user.TOTPEnabled {
+			return ErrMFANotEnabled
+		}
+
+		if err := s.authService.checkLoginThrottle(ctx, user.Email); err != nil {
+			return err
+		}
+
+		if !utils.CheckPassword(password, user.Password) {
+			return ErrInvalidCredentials
+		}
+
+		ok, err := verifySecondFactor(ctx, s.cfg, s.users, s.recoveryCodes, user, code)
+		if err != nil {
+			return apperror.ErrInternal.Wrap(err)
+		}
+		if !ok {
+			return ErrInvalidMFACode
+		}
+
+		if err := s.users.DisableTOTP(ctx, user.ID); err != nil {
+			return apperror.ErrInternal.Wrap(err)
+		}
+
+		if err := s.recoveryCodes.DeleteForUser(ctx, user.ID); err != nil {
+			return apperror.ErrInternal.Wrap(err)
+		}
+
+		return nil
+	})
+	if errors.Is(err, ErrInvalidCredentials) || errors.Is(err, ErrInvalidMFACode) {
+		// Recorded after the transaction has ended: recording runs its own,
+		// which would wait forever on the write lock held by this one.
+		return s.authService.loginFailed(user.Email, user, err)
+	}
+	return err
+}
+
+func (s *MFAService) findUser(ctx context.Context, userID uint) (*models.User, error) {
+	user, err := s.users.FindByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, apperror.ErrInternal.Wrap(err)
+	}
+	return user, nil
+}
+
+// verifySecondFactor accepts either a current TOTP code or an unused recovery
+// code and consumes it so it cannot be used again.
+func verifySecondFactor(
+	ctx context.Context,
+	cfg *config.Config,
+	users repositories.UserRepository,
+	recoveryCodes repositories.RecoveryCodeRepository,
+	user *models.User,
+	code string,
+) (bool, error) {
+	secret, err := utils.DecryptTOTPSecret(user.TOTPSecret, cfg.MFAEncryptionKey)
+	if err != nil {
+		return false, err
+	}
+
+	if step, ok := utils.ValidateTOTP(secret, code, time.Now(), user.TOTPLastStep); ok {
+		if err := users.SetTOTPLastStep(ctx, user.ID, step); err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+
+	return recoveryCodes.Consume(ctx, user.ID, utils.HashRecoveryCode(code), time.Now())
+}
+
+func hashRecoveryCodes(codes []string) []string {
+	hashes := make([]string, len(codes))
+	for i, code := range codes {
+		hashes[i] = utils.HashRecoveryCode(code)
+	}
+	return hashes
+}
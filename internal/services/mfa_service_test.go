@@ -0,0 +1,81 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"go-fiber-boilerplate/utils"
+)
+
+func TestEnrollEncryptsSecret(t *testing.T) {
+	auth, repos := newTestAuthService(t)
+	svc := NewMFAService(auth.cfg, repos.Transactor, repos.Users, repos.RecoveryCodes, auth)
+	user := register(t, auth, "ivan@example.com")
+
+	resp, err := svc.Enroll(user.ID)
+	if err != nil {
+		t.Fatalf("Enroll() error = %v", err)
+	}
+
+	stored, err := repos.Users.FindByID(context.Background(), user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(stored.TOTPSecret, resp.Secret) {
+		t.Fatalf("stored secret %q contains the plaintext secret", stored.TOTPSecret)
+	}
+
+	secret, err := utils.DecryptTOTPSecret(stored.TOTPSecret, auth.cfg.MFAEncryptionKey)
+	if err != nil || secret != resp.Secret {
+		t.Errorf("DecryptTOTPSecret() = %q, %v; want %q", secret, err, resp.Secret)
+	}
+	if _, err := utils.DecryptTOTPSecret(stored.TOTPSecret, "another-key"); err == nil {
+		t.Error("DecryptTOTPSecret() with another key succeeded")
+	}
+
+	// Secrets enrolled before encryption are still read as plaintext.
+	if secret, err := utils.DecryptTOTPSecret(resp.Secret, auth.cfg.MFAEncryptionKey); err != nil || secret != resp.Secret {
+		t.Errorf("DecryptTOTPSecret(plaintext) = %q, %v; want it unchanged", secret, err)
+	}
+}
+
+func TestDisableThrottle(t *testing.T) {
+	auth, repos := newTestAuthService(t)
+	svc := NewMFAService(auth.cfg, repos.Transactor, repos.Users, repos.RecoveryCodes, auth)
+	user := register(t, auth, "judy@example.com")
+	codes := enableMFA(t, auth, repos, user.ID)
+
+	if err := svc.Disable(user.ID, "Wr0ngPass!", codes[0]); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("Disable() with a wrong password error = %v, want ErrInvalidCredentials", err)
+	}
+	for i := 1; i <= loginFreeAttempts; i++ {
+		if err := svc.Disable(user.ID, testPassword, "wrong-code"); !errors.Is(err, ErrInvalidMFACode) {
+			t.Fatalf("attempt %d error = %v, want ErrInvalidMFACode", i+1, err)
+		}
+	}
+
+	// The right code is refused until the backoff has passed.
+	var throttled *ThrottledError
+	if err := svc.Disable(user.ID, testPassword, codes[0]); !errors.As(err, &throttled) {
+		t.Fatalf("Disable() while throttled error = %v, want ThrottledError", err)
+	}
+	stored, err := repos.Users.FindByID(context.Background(), user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !stored.TOTPEnabled {
+		t.Error("MFA was disabled while throttled")
+	}
+
+	if err := repos.LoginThrottles.Delete(context.Background(), loginEmailHash(user.Email)); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.Disable(user.ID, testPassword, codes[0]); err != nil {
+		t.Fatalf("Disable() with a recovery code error = %v", err)
+	}
+	if stored, _ := repos.Users.FindByID(context.Background(), user.ID); stored.TOTPEnabled {
+		t.Error("MFA still enabled after Disable()")
+	}
+}
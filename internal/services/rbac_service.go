@@ -0,0 +1,110 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"go-fiber-boilerplate/internal/models"
+	"go-fiber-boilerplate/internal/repositories"
+	"go-fiber-boilerplate/pkg/apperror"
+)
+
+type RBACService struct {
+	transactor repositories.Transactor
+	users      repositories.UserRepository
+	roles      repositories.RoleRepository
+}
+
+func NewRBACService(transactor repositories.Transactor, users repositories.UserRepository, roles repositories.RoleRepository) *RBACService {
+	return &RBACService{transactor: transactor, users: users, roles: roles}
+}
+
+// UserPermissions returns the set of permission names granted to the user
+// through all of their roles.
+func (s *RBACService) UserPermissions(userID uint) (map[string]bool, error) {
+	names, err := s.roles.UserPermissions(context.Background(), userID)
+	if err != nil {
+		return nil, apperror.ErrInternal.Wrap(err)
+	}
+
+	permissions := make(map[string]bool, len(names))
+	for _, name := range names {
+		permissions[name] = true
+	}
+	return permissions, nil
+}
+
+func (s *RBACService) HasPermission(userID uint, permission string) (bool, error) {
+	permissions, err := s.UserPermissions(userID)
+	if err != nil {
+		return false, err
+	}
+	return permissions[permission], nil
+}
+
+// CanModify reports whether the user owns a resource or holds the permission
+// that allows acting on resources owned by anyone.
+func (s *RBACService) CanModify(userID, ownerID uint, permission string) (bool, error) {
+	if userID == ownerID {
+		return true, nil
+	}
+	return s.HasPermission(userID, permission)
+}
+
+func (s *RBACService) ListRoles() ([]models.Role, error) {
+	roles, err := s.roles.List(context.Background())
+	if err != nil {
+		return nil, apperror.ErrInternal.Wrap(err)
+	}
+	return roles, nil
+}
+
+// SetUserRoles replaces the roles of a user.
+func (s *RBACService) SetUserRoles(userID uint, roleNames []string) (*models.User, error) {
+	if len(roleNames) == 0 {
+		return nil, ErrRoleRequired
+	}
+
+	err := s.transactor.Transaction(context.Background(), func(ctx context.Context) error {
+		if _, err := s.users.FindByID(ctx, userID); err != nil {
+			if errors.Is(err, repositories.ErrNotFound) {
+				return ErrUserNotFound
+			}
+			return apperror.ErrInternal.Wrap(err)
+		}
+
+		roles, err := s.roles.FindByNames(ctx, roleNames)
+		if err != nil {
+			return apperror.ErrInternal.Wrap(err)
+		}
+		if len(roles) != len(uniqueStrings(roleNames)) {
+			return ErrUnknownRole
+		}
+
+		if err := s.roles.SetUserRoles(ctx, userID, roles); err != nil {
+			return apperror.ErrInternal.Wrap(err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.users.FindWithRoles(context.Background(), userID)
+	if err != nil {
+		return nil, apperror.ErrInternal.Wrap(err)
+	}
+	return user, nil
+}
+
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	var unique []string
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			unique = append(unique, v)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,42 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"go-fiber-boilerplate/internal/models"
+	"go-fiber-boilerplate/internal/repositories/memory"
+)
+
+func TestSetUserRoles(t *testing.T) {
+	repos := memory.New()
+	svc := NewRBACService(repos.Transactor, repos.Users, repos.Roles)
+	repos.Users.Put(models.User{ID: ownerID, Email: "owner@example.com", IsActive: true})
+	repos.Users.Put(models.User{ID: moderatorID, Email: "moderator@example.com", IsActive: true})
+
+	if allowed, err := svc.CanModify(moderatorID, ownerID, models.PermSamplesUpdateAny); err != nil || allowed {
+		t.Fatalf("CanModify() without roles = %v, %v; want false", allowed, err)
+	}
+
+	user, err := svc.SetUserRoles(moderatorID, []string{models.RoleModerator, models.RoleModerator})
+	if err != nil {
+		t.Fatalf("SetUserRoles() error = %v", err)
+	}
+	if len(user.Roles) != 1 || user.Roles[0].Name != models.RoleModerator {
+		t.Errorf("SetUserRoles() roles = %+v, want %s", user.Roles, models.RoleModerator)
+	}
+
+	if allowed, err := svc.CanModify(moderatorID, ownerID, models.PermSamplesUpdateAny); err != nil || !allowed {
+		t.Errorf("CanModify() as %s = %v, %v; want true", models.RoleModerator, allowed, err)
+	}
+	if allowed, err := svc.HasPermission(moderatorID, models.PermRolesManage); err != nil || allowed {
+		t.Errorf("HasPermission(%s) as %s = %v, %v; want false", models.PermRolesManage, models.RoleModerator, allowed, err)
+	}
+
+	if _, err := svc.SetUserRoles(moderatorID, []string{"owner"}); !errors.Is(err, ErrUnknownRole) {
+		t.Errorf("SetUserRoles(unknown role) error = %v, want ErrUnknownRole", err)
+	}
+	if _, err := svc.SetUserRoles(999, []string{models.RoleUser}); !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("SetUserRoles(unknown user) error = %v, want ErrUserNotFound", err)
+	}
+}
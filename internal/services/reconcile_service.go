@@ -0,0 +1,255 @@
+package services
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"go-fiber-boilerplate/config"
+	"go-fiber-boilerplate/internal/models"
+	"go-fiber-boilerplate/internal/repositories"
+	"go-fiber-boilerplate/internal/storage"
+)
+
+// ReconcileService finds stored assets no sample refers to any more: uploads
+// whose database write failed, replacements whose old file could not be
+// deleted and images of soft-deleted samples. It also retries the deletions
+// recorded in the pending deletion repository and purges expired resumable
+// uploads.
+type ReconcileService struct {
+	cfg              *config.Config
+	transactor       repositories.Transactor
+	samples          repositories.SampleRepository
+	pendingDeletions repositories.PendingDeletionRepository
+	storage          storage.Storage
+	uploadService    *UploadService
+}
+
+// ReconcileOptions controls a reconciliation run.
+type ReconcileOptions struct {
+	// Purge deletes the orphans found; otherwise they are only reported.
+	Purge bool
+	// MinAge skips assets younger than this, which may belong to a request
+	// that has uploaded its image but not yet committed the row.
+	MinAge time.Duration
+}
+
+// ReconcileReport summarises a reconciliation run.
+type ReconcileReport struct {
+	Scanned           int      `json:"scanned"`
+	Referenced        int      `json:"referenced"`
+	Orphans           []string `json:"orphans"`
+	Purged            int      `json:"purged"`
+	PurgeFailed       int      `json:"purge_failed"`
+	RetriedDeletions  int      `json:"retried_deletions"`
+	PendingDeletions  int      `json:"pending_deletions"`
+	ExpiredUploads    int      `json:"expired_uploads"`
+	SkippedTooRecent  int      `json:"skipped_too_recent"`
+	DurationMillis    int64    `json:"duration_ms"`
+	StorageUnlistable bool     `json:"storage_unlistable,omitempty"`
+}
+
+const (
+	pendingDeletionBaseDelay = time.Minute
+	pendingDeletionMaxDelay  = 24 * time.Hour
+	pendingDeletionBatch     = 100
+)
+
+func NewReconcileService(
+	cfg *config.Config,
+	transactor repositories.Transactor,
+	samples repositories.SampleRepository,
+	pendingDeletions repositories.PendingDeletionRepository,
+	store storage.Storage,
+	uploadService *UploadService,
+) *ReconcileService {
+	return &ReconcileService{
+		cfg:              cfg,
+		transactor:       transactor,
+		samples:          samples,
+		pendingDeletions: pendingDeletions,
+		storage:          store,
+		uploadService:    uploadService,
+	}
+}
+
+// Run retries pending deletions, purges expired uploads and then compares
+// the sample image folder with the public IDs referenced by live samples.
+func (s *ReconcileService) Run(ctx context.Context, opts ReconcileOptions) (*ReconcileReport, error) {
+	started := time.Now()
+	report := &ReconcileReport{Orphans: []string{}}
+
+	retried, err := s.RetryPendingDeletions(ctx)
+	report.RetriedDeletions = retried
+	if err != nil {
+		return report, err
+	}
+
+	if report.ExpiredUploads, err = s.uploadService.PurgeExpired(); err != nil {
+		return report, err
+	}
+
+	lister, ok := s.storage.(storage.Lister)
+	if !ok {
+		report.StorageUnlistable = true
+	} else if err := s.findOrphans(ctx, lister, opts, report); err != nil {
+		return report, err
+	}
+
+	if opts.Purge {
+		for _, publicID := range report.Orphans {
+			if err := s.purge(ctx, publicID); err != nil {
+				log.Printf("warning: failed to purge orphaned image %s: %v", publicID, err)
+				queueAssetDeletion(ctx, s.pendingDeletions, publicID, err)
+				report.PurgeFailed++
+				continue
+			}
+			report.Purged++
+		}
+	}
+
+	pending, err := s.pendingDeletions.Count(ctx)
+	if err != nil {
+		return report, err
+	}
+	report.PendingDeletions = int(pending)
+	report.DurationMillis = time.Since(started).Milliseconds()
+	return report, nil
+}
+
+// Start runs the reconciliation every interval until ctx is cancelled,
+// logging each report. A run in progress is abandoned when ctx is cancelled,
+// which is safe as every run starts from a fresh scan.
+func (s *ReconcileService) Start(ctx context.Context, interval time.Duration, opts ReconcileOptions) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			report, err := s.Run(ctx, opts)
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				log.Printf("asset reconciliation failed: %v", err)
+				continue
+			}
+			log.Printf("asset reconciliation: scanned=%d orphans=%d purged=%d purge_failed=%d retried=%d pending=%d expired_uploads=%d",
+				report.Scanned, len(report.Orphans), report.Purged, report.PurgeFailed,
+				report.RetriedDeletions, report.PendingDeletions, report.ExpiredUploads)
+		}
+	}
+}
+
+// RetryPendingDeletions retries the recorded deletions that are due and
+// returns how many succeeded. Failures are pushed back exponentially.
+func (s *ReconcileService) RetryPendingDeletions(ctx context.Context) (int, error) {
+	due, err := s.pendingDeletions.Due(ctx, time.Now(), pendingDeletionBatch)
+	if err != nil {
+		return 0, err
+	}
+
+	deleted := 0
+	for _, pending := range due {
+		if err := ctx.Err(); err != nil {
+			return deleted, err
+		}
+
+		if err := s.storage.Delete(ctx, pending.PublicID); err != nil {
+			pending.Attempts++
+			pending.LastError = err.Error()
+			pending.NextAttemptAt = time.Now().Add(pendingDeletionDelay(pending.Attempts))
+			if err := s.pendingDeletions.Save(ctx, &pending); err != nil {
+				return deleted, err
+			}
+			continue
+		}
+
+		if err := s.pendingDeletions.Delete(ctx, pending.PublicID); err != nil {
+			return deleted, err
+		}
+		deleted++
+	}
+	return deleted, nil
+}
+
+func (s *ReconcileService) findOrphans(ctx context.Context, lister storage.Lister, opts ReconcileOptions, report *ReconcileReport) error {
+	var assets []storage.Asset
+	if err := lister.List(ctx, sampleImageFolder, func(asset storage.Asset) error {
+		assets = append(assets, asset)
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	// References are loaded after listing, so an asset committed while the
+	// listing ran is still seen as referenced.
+	publicIDs, err := s.samples.ReferencedAssets(ctx)
+	if err != nil {
+		return err
+	}
+	referenced := make(map[string]bool, len(publicIDs))
+	for _, publicID := range publicIDs {
+		referenced[publicID] = true
+	}
+	report.Referenced = len(referenced)
+
+	cutoff := time.Now().Add(-opts.MinAge)
+	for _, asset := range assets {
+		report.Scanned++
+		if referenced[asset.PublicID] {
+			continue
+		}
+		if asset.CreatedAt.After(cutoff) {
+			report.SkippedTooRecent++
+			continue
+		}
+		report.Orphans = append(report.Orphans, asset.PublicID)
+	}
+	return nil
+}
+
+// purge deletes an orphan and forgets it everywhere it may still be
+// mentioned: the retry queue and soft-deleted samples.
+func (s *ReconcileService) purge(ctx context.Context, publicID string) error {
+	if err := s.storage.Delete(ctx, publicID); err != nil {
+		return err
+	}
+
+	return s.transactor.Transaction(ctx, func(ctx context.Context) error {
+		if err := s.pendingDeletions.Delete(ctx, publicID); err != nil {
+			return err
+		}
+		return s.samples.ForgetAsset(ctx, publicID)
+	})
+}
+
+// queueAssetDeletion records a failed deletion so the reconciliation job
+// retries it. Recording an asset that is already queued keeps its schedule.
+func queueAssetDeletion(ctx context.Context, pendingDeletions repositories.PendingDeletionRepository, publicID string, cause error) {
+	if publicID == "" {
+		return
+	}
+
+	if err := pendingDeletions.Queue(ctx, &models.PendingAssetDeletion{
+		PublicID:      publicID,
+		Attempts:      1,
+		LastError:     cause.Error(),
+		NextAttemptAt: time.Now().Add(pendingDeletionDelay(1)),
+	}); err != nil {
+		log.Printf("warning: failed to queue deletion of %s: %v", publicID, err)
+	}
+}
+
+// pendingDeletionDelay is the wait before the next attempt after attempts
+// failures: one minute, doubling up to a day.
+func pendingDeletionDelay(attempts int) time.Duration {
+	delay := pendingDeletionBaseDelay
+	for i := 1; i < attempts && delay < pendingDeletionMaxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, pendingDeletionMaxDelay)
+}
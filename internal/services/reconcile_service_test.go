@@ -0,0 +1,93 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go-fiber-boilerplate/internal/models"
+	"go-fiber-boilerplate/internal/repositories/memory"
+	"go-fiber-boilerplate/internal/storage"
+)
+
+// deletingStorage is a storage.Storage that records deletions and fails the
+// ones listed in broken.
+type deletingStorage struct {
+	unreachableStorage
+	deleted []string
+	broken  map[string]bool
+}
+
+func (s *deletingStorage) Delete(ctx context.Context, publicID string) error {
+	if s.broken[publicID] {
+		return errStorageDown
+	}
+	s.deleted = append(s.deleted, publicID)
+	return nil
+}
+
+func newTestReconcileService(t *testing.T, store storage.Storage) (*ReconcileService, *memory.Repositories) {
+	t.Helper()
+
+	repos := memory.New()
+	cfg := testConfig()
+	cfg.TusUploadDir = t.TempDir()
+	uploads := NewUploadService(cfg, repos.Uploads)
+	return NewReconcileService(cfg, repos.Transactor, repos.Samples, repos.PendingDeletions, store, uploads), repos
+}
+
+func TestRetryPendingDeletions(t *testing.T) {
+	store := &deletingStorage{broken: map[string]bool{"samples/stuck": true}}
+	svc, repos := newTestReconcileService(t, store)
+
+	ctx := context.Background()
+	due := time.Now().Add(-time.Minute)
+	for _, pending := range []models.PendingAssetDeletion{
+		{PublicID: "samples/gone", Attempts: 1, NextAttemptAt: due},
+		{PublicID: "samples/stuck", Attempts: 3, NextAttemptAt: due},
+		{PublicID: "samples/later", Attempts: 1, NextAttemptAt: time.Now().Add(time.Hour)},
+	} {
+		if err := repos.PendingDeletions.Queue(ctx, &pending); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	deleted, err := svc.RetryPendingDeletions(ctx)
+	if err != nil || deleted != 1 {
+		t.Fatalf("RetryPendingDeletions() = %d, %v; want 1", deleted, err)
+	}
+	if len(store.deleted) != 1 || store.deleted[0] != "samples/gone" {
+		t.Errorf("deleted assets = %v, want only the due one that succeeds", store.deleted)
+	}
+
+	if _, ok := repos.PendingDeletions.Find("samples/gone"); ok {
+		t.Error("successful deletion is still queued")
+	}
+	stuck, ok := repos.PendingDeletions.Find("samples/stuck")
+	if !ok || stuck.Attempts != 4 || stuck.LastError != errStorageDown.Error() {
+		t.Fatalf("failed retry = %+v, want a fourth attempt recorded", stuck)
+	}
+	if wait := time.Until(stuck.NextAttemptAt); wait < pendingDeletionDelay(4)-time.Minute {
+		t.Errorf("failed retry rescheduled in %v, want about %v", wait, pendingDeletionDelay(4))
+	}
+	if _, ok := repos.PendingDeletions.Find("samples/later"); !ok {
+		t.Error("deletion that is not due yet was dropped")
+	}
+}
+
+func TestReconcileUnlistableStorage(t *testing.T) {
+	svc, repos := newTestReconcileService(t, &deletingStorage{})
+
+	pending := models.PendingAssetDeletion{PublicID: "samples/later", NextAttemptAt: time.Now().Add(time.Hour)}
+	if err := repos.PendingDeletions.Queue(context.Background(), &pending); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := svc.Run(context.Background(), ReconcileOptions{Purge: true})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if !report.StorageUnlistable || report.Scanned != 0 || report.PendingDeletions != 1 {
+		t.Errorf("Run() = %+v, want an unlistable storage and one pending deletion", report)
+	}
+}
@@ -0,0 +1,403 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"go-fiber-boilerplate/internal/models"
+	"go-fiber-boilerplate/internal/repositories"
+	"go-fiber-boilerplate/internal/storage"
+	"go-fiber-boilerplate/pkg/apperror"
+)
+
+// Gallery operations of SampleService. Images are stored first and the rows
+// written in a transaction afterwards; replaced or removed assets are only
+// deleted from storage once that transaction has committed.
+
+const maxSampleImages = 20
+
+// ImageInput is a new gallery image, given either as a multipart file or as
+// the ID of a completed resumable upload.
+type ImageInput struct {
+	File     *multipart.FileHeader
+	UploadID string
+	AltText  string
+}
+
+func (in ImageInput) present() bool {
+	return in.File != nil || in.UploadID != ""
+}
+
+// storedImage is an image already written to storage but not yet recorded.
+// done releases the resumable upload it came from, if any.
+type storedImage struct {
+	object *storage.Object
+	done   func()
+}
+
+func (i *storedImage) model(sampleID uint, position int, altText string) *models.SampleImage {
+	return &models.SampleImage{
+		SampleID: sampleID,
+		Position: position,
+		URL:      i.object.URL,
+		PublicID: i.object.PublicID,
+		AltText:  altText,
+		Width:    i.object.Width,
+		Height:   i.object.Height,
+		Variants: i.variants(),
+	}
+}
+
+// variants returns the renditions storage reported for the image.
+func (i *storedImage) variants() []models.ImageVariant {
+	variants := make([]models.ImageVariant, 0, len(i.object.Variants))
+	for _, variant := range i.object.Variants {
+		variants = append(variants, models.ImageVariant{
+			Name:   variant.Name,
+			URL:    variant.URL,
+			Width:  variant.Width,
+			Height: variant.Height,
+		})
+	}
+	return variants
+}
+
+// AddImage appends an image to the end of the sample's gallery.
+func (s *SampleService) AddImage(userID uint, sampleID int, in ImageInput) (*models.SampleImage, error) {
+	sample, err := s.modifiableSample(userID, sampleID, models.PermSamplesUpdateAny)
+	if err != nil {
+		return nil, err
+	}
+	if !in.present() {
+		return nil, ErrImageRequired
+	}
+
+	image, err := s.storeImage(userID, in)
+	if err != nil {
+		return nil, err
+	}
+
+	var record *models.SampleImage
+	if err := s.transactor.Transaction(context.Background(), func(ctx context.Context) error {
+		if err := s.samples.Lock(ctx, sample.ID); err != nil {
+			return err
+		}
+
+		count, err := s.samples.CountImages(ctx, sample.ID)
+		if err != nil {
+			return err
+		}
+		if count >= maxSampleImages {
+			return ErrGalleryFull
+		}
+
+		record = image.model(sample.ID, int(count), in.AltText)
+		if err := s.samples.CreateImage(ctx, record); err != nil {
+			return err
+		}
+		return s.samples.SyncCover(ctx, sample.ID)
+	}); err != nil {
+		s.deleteAsset(image.object.PublicID)
+		if errors.Is(err, ErrGalleryFull) {
+			return nil, ErrGalleryFull
+		}
+		return nil, apperror.ErrInternal.Wrap(err)
+	}
+
+	image.done()
+	return record, nil
+}
+
+// ReplaceImage swaps the file behind a gallery image, keeping its position.
+// The alt text is updated when given; with no new file only the alt text
+// changes.
+func (s *SampleService) ReplaceImage(userID uint, sampleID, imageID int, in ImageInput) (*models.SampleImage, error) {
+	sample, err := s.modifiableSample(userID, sampleID, models.PermSamplesUpdateAny)
+	if err != nil {
+		return nil, err
+	}
+	record, err := s.findImage(sample.ID, imageID)
+	if err != nil {
+		return nil, err
+	}
+	if !in.present() && in.AltText == "" {
+		return nil, ErrImageRequired
+	}
+
+	var image *storedImage
+	if in.present() {
+		if image, err = s.storeImage(userID, in); err != nil {
+			return nil, err
+		}
+	}
+
+	replaced := record.PublicID
+	if in.AltText != "" {
+		record.AltText = in.AltText
+	}
+	if image != nil {
+		record.URL = image.object.URL
+		record.PublicID = image.object.PublicID
+		record.Width = image.object.Width
+		record.Height = image.object.Height
+		record.Variants = image.variants()
+	}
+
+	if err := s.transactor.Transaction(context.Background(), func(ctx context.Context) error {
+		if err := s.samples.SaveImage(ctx, record); err != nil {
+			return err
+		}
+		return s.samples.SyncCover(ctx, sample.ID)
+	}); err != nil {
+		if image != nil {
+			s.deleteAsset(image.object.PublicID)
+		}
+		return nil, apperror.ErrInternal.Wrap(err)
+	}
+
+	if image != nil {
+		image.done()
+		s.deleteAsset(replaced)
+	}
+	return record, nil
+}
+
+// ReorderImages puts the gallery in the given order. imageIDs must list every
+// image of the sample exactly once.
+func (s *SampleService) ReorderImages(userID uint, sampleID int, imageIDs []uint) ([]models.SampleImage, error) {
+	sample, err := s.modifiableSample(userID, sampleID, models.PermSamplesUpdateAny)
+	if err != nil {
+		return nil, err
+	}
+
+	var images []models.SampleImage
+	if err := s.transactor.Transaction(context.Background(), func(ctx context.Context) error {
+		if err := s.samples.Lock(ctx, sample.ID); err != nil {
+			return err
+		}
+
+		existing, err := s.samples.Images(ctx, sample.ID)
+		if err != nil {
+			return err
+		}
+		existingIDs := make([]uint, len(existing))
+		for i, image := range existing {
+			existingIDs[i] = image.ID
+		}
+		if !samePermutation(existingIDs, imageIDs) {
+			return ErrInvalidImageOrder
+		}
+
+		if err := s.samples.SetImageOrder(ctx, sample.ID, imageIDs); err != nil {
+			return err
+		}
+		if err := s.samples.SyncCover(ctx, sample.ID); err != nil {
+			return err
+		}
+		images, err = s.samples.Images(ctx, sample.ID)
+		return err
+	}); err != nil {
+		if errors.Is(err, ErrInvalidImageOrder) {
+			return nil, ErrInvalidImageOrder
+		}
+		return nil, apperror.ErrInternal.Wrap(err)
+	}
+
+	return images, nil
+}
+
+// DeleteImage removes an image from the gallery and from storage.
+func (s *SampleService) DeleteImage(userID uint, sampleID, imageID int) error {
+	sample, err := s.modifiableSample(userID, sampleID, models.PermSamplesUpdateAny)
+	if err != nil {
+		return err
+	}
+	record, err := s.findImage(sample.ID, imageID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.transactor.Transaction(context.Background(), func(ctx context.Context) error {
+		if err := s.samples.Lock(ctx, sample.ID); err != nil {
+			return err
+		}
+		if err := s.samples.DeleteImage(ctx, record); err != nil {
+			return err
+		}
+		return s.samples.SyncCover(ctx, sample.ID)
+	}); err != nil {
+		return apperror.ErrInternal.Wrap(err)
+	}
+
+	s.deleteAsset(record.PublicID)
+	return nil
+}
+
+// storeImage writes an image to storage from a multipart file or a completed
+// resumable upload.
+func (s *SampleService) storeImage(userID uint, in ImageInput) (*storedImage, error) {
+	if in.File != nil {
+		object, err := s.uploadImage(in.File)
+		if err != nil {
+			return nil, err
+		}
+		return &storedImage{object: object, done: func() {}}, nil
+	}
+
+	file, upload, err := s.uploadService.Open(userID, in.UploadID)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	contentType, err := detectImageType(file)
+	if err != nil {
+		return nil, err
+	}
+
+	object, err := s.putImage(file, upload.Filename, contentType, upload.Length)
+	if err != nil {
+		return nil, err
+	}
+	return &storedImage{
+		object: object,
+		done: func() {
+			if err := s.uploadService.remove(upload.ID); err != nil {
+				log.Printf("warning: failed to remove upload %s: %v", upload.ID, err)
+			}
+		},
+	}, nil
+}
+
+func (s *SampleService) uploadImage(file *multipart.FileHeader) (*storage.Object, error) {
+	if !sampleImageExtensions[strings.ToLower(filepath.Ext(file.Filename))] {
+		return nil, ErrInvalidImage
+	}
+	if file.Size > maxSampleImageSize {
+		return nil, ErrImageTooLarge
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		return nil, ErrImageUpload.Wrap(fmt.Errorf("failed to open file: %w", err))
+	}
+	defer src.Close()
+
+	contentType, err := detectImageType(src)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.putImage(src, file.Filename, contentType, file.Size)
+}
+
+// detectImageType sniffs the content type of file and rewinds it. Only JPEG
+// and PNG are accepted, whatever the extension or declared type says, so a
+// multipart upload and a resumable one accept the same images.
+func detectImageType(file io.ReadSeeker) (string, error) {
+	head := make([]byte, 512)
+	n, _ := io.ReadFull(file, head)
+	contentType := http.DetectContentType(head[:n])
+	if contentType != "image/jpeg" && contentType != "image/png" {
+		return "", ErrInvalidImage
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return "", apperror.ErrInternal.Wrap(err)
+	}
+	return contentType, nil
+}
+
+func (s *SampleService) putImage(body io.Reader, filename, contentType string, size int64) (*storage.Object, error) {
+	object, err := s.storage.Put(context.Background(), body, storage.PutInput{
+		Folder:      sampleImageFolder,
+		Filename:    filename,
+		ContentType: contentType,
+		Size:        size,
+	})
+	if err != nil {
+		if errors.Is(err, storage.ErrUnsupportedImage) {
+			return nil, ErrInvalidImage
+		}
+		if errors.Is(err, storage.ErrImageTooLarge) {
+			return nil, ErrImageDimensions
+		}
+		return nil, ErrImageUpload.Wrap(err)
+	}
+	return object, nil
+}
+
+// deleteAsset removes an asset that is no longer referenced. A failure is
+// queued for the reconciliation job to retry instead of failing the request.
+func (s *SampleService) deleteAsset(publicID string) {
+	if err := s.storage.Delete(context.Background(), publicID); err != nil {
+		log.Printf("warning: failed to delete image %s: %v", publicID, err)
+		queueAssetDeletion(context.Background(), s.pendingDeletions, publicID, err)
+	}
+}
+
+// replaceCover stores image as the first gallery image, replacing the file of
+// the current cover if there is one. It returns the replaced public ID and
+// must run in a transaction.
+func (s *SampleService) replaceCover(ctx context.Context, sampleID uint, image *storedImage) (string, error) {
+	if err := s.samples.Lock(ctx, sampleID); err != nil {
+		return "", err
+	}
+
+	images, err := s.samples.Images(ctx, sampleID)
+	if err != nil {
+		return "", err
+	}
+	if len(images) == 0 {
+		if err := s.samples.CreateImage(ctx, image.model(sampleID, 0, "")); err != nil {
+			return "", err
+		}
+		return "", s.samples.SyncCover(ctx, sampleID)
+	}
+
+	cover := images[0]
+	replaced := cover.PublicID
+	cover.URL = image.object.URL
+	cover.PublicID = image.object.PublicID
+	cover.Width = image.object.Width
+	cover.Height = image.object.Height
+	cover.Variants = image.variants()
+	if err := s.samples.SaveImage(ctx, &cover); err != nil {
+		return "", err
+	}
+	return replaced, s.samples.SyncCover(ctx, sampleID)
+}
+
+func (s *SampleService) findImage(sampleID uint, imageID int) (*models.SampleImage, error) {
+	image, err := s.samples.FindImage(context.Background(), sampleID, uint(imageID))
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, ErrSampleImageNotFound
+		}
+		return nil, apperror.ErrInternal.Wrap(err)
+	}
+	return image, nil
+}
+
+func samePermutation(existing, ordered []uint) bool {
+	if len(existing) != len(ordered) {
+		return false
+	}
+	seen := make(map[uint]bool, len(existing))
+	for _, id := range existing {
+		seen[id] = true
+	}
+	for _, id := range ordered {
+		if !seen[id] {
+			return false
+		}
+		delete(seen, id)
+	}
+	return true
+}
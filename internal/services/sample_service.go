@@ -1,56 +1,73 @@
 package services
 
 import (
+	"context"
 	"errors"
-	"log"
 	"mime/multipart"
 
 	"go-fiber-boilerplate/config"
-	"go-fiber-boilerplate/database"
 	"go-fiber-boilerplate/internal/models"
+	"go-fiber-boilerplate/internal/repositories"
+	"go-fiber-boilerplate/internal/storage"
+	"go-fiber-boilerplate/pkg/apperror"
 	"go-fiber-boilerplate/pkg/pagination"
-
-	"gorm.io/gorm"
 )
 
+// SampleService keeps samples and their galleries in the sample repository.
+// Asset deletions that fail are queued in the pending deletion repository.
 type SampleService struct {
-	cfg               *config.Config
-	cloudinaryService *CloudinaryService
+	cfg              *config.Config
+	transactor       repositories.Transactor
+	samples          repositories.SampleRepository
+	pendingDeletions repositories.PendingDeletionRepository
+	storage          storage.Storage
+	permissions      PermissionChecker
+	uploadService    *UploadService
 }
 
-var sampleSortableColumns = map[string]string{
-	"created_at": "created_at",
-	"updated_at": "updated_at",
-	"title":      "title",
-	"id":         "id",
+// PermissionChecker decides whether a user may act on a resource owned by
+// someone else. RBACService implements it.
+type PermissionChecker interface {
+	CanModify(userID, ownerID uint, permission string) (bool, error)
 }
 
-func NewSampleService(cfg *config.Config) *SampleService {
-	cloudinaryService, err := NewCloudinaryService(cfg)
-	if err != nil {
-		log.Printf("Cloudinary service disabled: %v", err)
-	}
+const (
+	sampleImageFolder  = "samples"
+	maxSampleImageSize = 5 * 1024 * 1024
+)
+
+// sampleImageExtensions are the extensions of the formats every storage
+// backend processes into variants, JPEG and PNG.
+var sampleImageExtensions = map[string]bool{
+	".jpg":  true,
+	".jpeg": true,
+	".png":  true,
+}
+
+func NewSampleService(
+	cfg *config.Config,
+	transactor repositories.Transactor,
+	samples repositories.SampleRepository,
+	pendingDeletions repositories.PendingDeletionRepository,
+	store storage.Storage,
+	permissions PermissionChecker,
+	uploadService *UploadService,
+) *SampleService {
 	return &SampleService{
-		cfg:               cfg,
-		cloudinaryService: cloudinaryService,
+		cfg:              cfg,
+		transactor:       transactor,
+		samples:          samples,
+		pendingDeletions: pendingDeletions,
+		storage:          store,
+		permissions:      permissions,
+		uploadService:    uploadService,
 	}
 }
 
 func (s *SampleService) GetSamples(params pagination.Params) ([]models.Sample, pagination.Meta, error) {
-	var samples []models.Sample
-	var total int64
-
-	if err := database.GetDB().Model(&models.Sample{}).Count(&total).Error; err != nil {
-		return nil, pagination.Meta{}, err
-	}
-
-	if err := database.GetDB().
-		Preload("User").
-		Offset(params.Offset()).
-		Limit(params.PerPage).
-		Order(params.OrderClause("created_at", sampleSortableColumns)).
-		Find(&samples).Error; err != nil {
-		return nil, pagination.Meta{}, err
+	samples, total, err := s.samples.List(repositories.ReadOnly(context.Background()), params)
+	if err != nil {
+		return nil, pagination.Meta{}, apperror.ErrInternal.Wrap(err)
 	}
 
 	meta := pagination.BuildMeta(total, params)
@@ -58,22 +75,23 @@ func (s *SampleService) GetSamples(params pagination.Params) ([]models.Sample, p
 }
 
 func (s *SampleService) GetSampleById(id int) (*models.Sample, error) {
-	var sample models.Sample
-	if err := database.GetDB().
-		Preload("User").
-		First(&sample, id).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, gorm.ErrRecordNotFound
+	sample, err := s.samples.FindWithDetails(repositories.ReadOnly(context.Background()), uint(id))
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, ErrSampleNotFound
 		}
-		return nil, err
+		return nil, apperror.ErrInternal.Wrap(err)
 	}
-	return &sample, nil
+	return sample, nil
 }
 
 func (s *SampleService) CreateSample(userID uint, req models.CreateSampleRequest, imageFile *multipart.FileHeader) (*models.Sample, error) {
-	var existingBlog models.Sample
-	if err := database.GetDB().Where("title = ?", req.Title).First(&existingBlog).Error; err == nil {
-		return nil, errors.New("title already exists")
+	ctx := context.Background()
+
+	if taken, err := s.samples.TitleExists(ctx, req.Title); err != nil {
+		return nil, apperror.ErrInternal.Wrap(err)
+	} else if taken {
+		return nil, ErrTitleTaken
 	}
 	sample := models.Sample{
 		Title:       req.Title,
@@ -82,42 +100,45 @@ func (s *SampleService) CreateSample(userID uint, req models.CreateSampleRequest
 	}
 
 	// Handle image upload if provided
-	if imageFile != nil && s.cloudinaryService != nil {
-		uploadResult, err := s.cloudinaryService.UploadImage(imageFile, "samples")
-		if err != nil {
-			return nil, errors.New("failed to upload image: " + err.Error())
+	var image *storedImage
+	if imageFile != nil {
+		var err error
+		if image, err = s.storeImage(userID, ImageInput{File: imageFile}); err != nil {
+			return nil, err
 		}
-		sample.ImageURL = uploadResult.SecureURL
-		sample.ImagePublicID = uploadResult.PublicID
 	}
 
-	if err := database.GetDB().Create(&sample).Error; err != nil {
+	if err := s.transactor.Transaction(ctx, func(ctx context.Context) error {
+		if err := s.samples.Create(ctx, &sample); err != nil {
+			return err
+		}
+		if image == nil {
+			return nil
+		}
+		if err := s.samples.CreateImage(ctx, image.model(sample.ID, 0, "")); err != nil {
+			return err
+		}
+		return s.samples.SyncCover(ctx, sample.ID)
+	}); err != nil {
 		// Cleanup image if database save fails
-		if sample.ImagePublicID != "" && s.cloudinaryService != nil {
-			s.cloudinaryService.DeleteImage(sample.ImagePublicID)
+		if image != nil {
+			s.deleteAsset(image.object.PublicID)
 		}
-		return nil, err
+		return nil, apperror.ErrInternal.Wrap(err)
+	}
+	if image != nil {
+		image.done()
 	}
 
-	database.GetDB().Preload("User").First(&sample, sample.ID)
-	return &sample, nil
+	return s.reload(sample.ID)
 }
 
 func (s *SampleService) UpdateSample(userID uint, id int, req models.UpdateSampleRequest, imageFile *multipart.FileHeader) (*models.Sample, error) {
-	var sample models.Sample
-	if err := database.GetDB().First(&sample, id).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, gorm.ErrRecordNotFound
-		}
+	sample, err := s.modifiableSample(userID, id, models.PermSamplesUpdateAny)
+	if err != nil {
 		return nil, err
 	}
 
-	if sample.UserID != userID {
-		return nil, errors.New("forbidden")
-	}
-
-	oldImagePublicID := sample.ImagePublicID
-
 	if req.Title != "" {
 		sample.Title = req.Title
 	}
@@ -125,53 +146,119 @@ func (s *SampleService) UpdateSample(userID uint, id int, req models.UpdateSampl
 		sample.Description = req.Description
 	}
 
-	// Handle new image upload
-	if imageFile != nil && s.cloudinaryService != nil {
-		uploadResult, err := s.cloudinaryService.UploadImage(imageFile, "samples")
-		if err != nil {
-			return nil, errors.New("failed to upload image: " + err.Error())
+	// A new image replaces the cover
+	var image *storedImage
+	if imageFile != nil {
+		if image, err = s.storeImage(userID, ImageInput{File: imageFile}); err != nil {
+			return nil, err
 		}
-		sample.ImageURL = uploadResult.SecureURL
-		sample.ImagePublicID = uploadResult.PublicID
 	}
 
-	if err := database.GetDB().Save(&sample).Error; err != nil {
+	var replaced string
+	if err := s.transactor.Transaction(context.Background(), func(ctx context.Context) error {
+		if err := s.samples.Save(ctx, sample); err != nil {
+			return err
+		}
+		if image == nil {
+			return nil
+		}
+		replaced, err = s.replaceCover(ctx, sample.ID, image)
+		return err
+	}); err != nil {
+		if image != nil {
+			s.deleteAsset(image.object.PublicID)
+		}
+		return nil, apperror.ErrInternal.Wrap(err)
+	}
+
+	// Delete old image if new one was saved successfully
+	if image != nil {
+		image.done()
+	}
+	if replaced != "" {
+		s.deleteAsset(replaced)
+	}
+
+	return s.reload(sample.ID)
+}
+
+// AttachUpload makes a completed resumable upload the sample's cover image
+// and discards the upload afterwards.
+func (s *SampleService) AttachUpload(userID uint, id int, uploadID string) (*models.Sample, error) {
+	sample, err := s.modifiableSample(userID, id, models.PermSamplesUpdateAny)
+	if err != nil {
 		return nil, err
 	}
 
-	// Delete old image if new one was uploaded successfully
-	if imageFile != nil && oldImagePublicID != "" && s.cloudinaryService != nil {
-		if err := s.cloudinaryService.DeleteImage(oldImagePublicID); err != nil {
-			log.Printf("warning: failed to delete old image %s: %v", oldImagePublicID, err)
-		}
+	image, err := s.storeImage(userID, ImageInput{UploadID: uploadID})
+	if err != nil {
+		return nil, err
 	}
 
-	database.GetDB().Preload("User").First(&sample, sample.ID)
-	return &sample, nil
+	var replaced string
+	if err := s.transactor.Transaction(context.Background(), func(ctx context.Context) error {
+		replaced, err = s.replaceCover(ctx, sample.ID, image)
+		return err
+	}); err != nil {
+		s.deleteAsset(image.object.PublicID)
+		return nil, apperror.ErrInternal.Wrap(err)
+	}
+
+	image.done()
+	if replaced != "" {
+		s.deleteAsset(replaced)
+	}
+
+	return s.reload(sample.ID)
 }
 
 func (s *SampleService) DeleteSample(userID uint, id int) error {
-	var sample models.Sample
-	if err := database.GetDB().First(&sample, id).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return gorm.ErrRecordNotFound
-		}
+	sample, err := s.modifiableSample(userID, id, models.PermSamplesDeleteAny)
+	if err != nil {
 		return err
 	}
 
-	if sample.UserID != userID {
-		return errors.New("forbidden")
+	publicIDs, err := s.samples.Delete(context.Background(), sample)
+	if err != nil {
+		return apperror.ErrInternal.Wrap(err)
+	}
+
+	// Assets go only after the rows are gone, so a failure here never leaves a
+	// sample pointing at a deleted image.
+	for _, publicID := range publicIDs {
+		s.deleteAsset(publicID)
 	}
 
-	if sample.ImagePublicID != "" && s.cloudinaryService != nil {
-		if err := s.cloudinaryService.DeleteImage(sample.ImagePublicID); err != nil {
-			log.Printf("warning: failed to delete cloudinary image %s: %v", sample.ImagePublicID, err)
+	return nil
+}
+
+// modifiableSample loads a sample the user may change with the given
+// "any" permission, or owns.
+func (s *SampleService) modifiableSample(userID uint, id int, permission string) (*models.Sample, error) {
+	sample, err := s.samples.FindByID(context.Background(), uint(id))
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, ErrSampleNotFound
 		}
+		return nil, apperror.ErrInternal.Wrap(err)
 	}
 
-	if err := database.GetDB().Delete(&sample).Error; err != nil {
-		return err
+	allowed, err := s.permissions.CanModify(userID, sample.UserID, permission)
+	if err != nil {
+		return nil, err
 	}
+	if !allowed {
+		return nil, ErrSampleForbidden
+	}
+	return sample, nil
+}
 
-	return nil
+// reload reads a sample back from the primary, so the response reflects the
+// write that preceded it.
+func (s *SampleService) reload(id uint) (*models.Sample, error) {
+	sample, err := s.samples.FindWithDetails(context.Background(), id)
+	if err != nil {
+		return nil, apperror.ErrInternal.Wrap(err)
+	}
+	return sample, nil
 }
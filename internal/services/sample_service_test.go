@@ -0,0 +1,193 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"go-fiber-boilerplate/internal/models"
+	"go-fiber-boilerplate/internal/repositories/memory"
+	"go-fiber-boilerplate/internal/storage"
+	"go-fiber-boilerplate/pkg/pagination"
+)
+
+// grants is a PermissionChecker granting each user the listed permissions.
+type grants map[uint][]string
+
+func (g grants) CanModify(userID, ownerID uint, permission string) (bool, error) {
+	if userID == ownerID {
+		return true, nil
+	}
+	for _, granted := range g[userID] {
+		if granted == permission {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+const (
+	ownerID uint = iota + 1
+	strangerID
+	moderatorID
+)
+
+// unreachableStorage is a storage.Storage whose every call fails.
+type unreachableStorage struct{}
+
+var errStorageDown = errors.New("storage unreachable")
+
+func (unreachableStorage) Put(context.Context, io.Reader, storage.PutInput) (*storage.Object, error) {
+	return nil, errStorageDown
+}
+
+func (unreachableStorage) Delete(context.Context, string) error {
+	return errStorageDown
+}
+
+func (unreachableStorage) URL(publicID, variant string) string {
+	return publicID
+}
+
+func (unreachableStorage) Variants(publicID string) map[string]string {
+	return nil
+}
+
+func newTestSampleService(t *testing.T) (*SampleService, *memory.Repositories) {
+	t.Helper()
+
+	repos := memory.New()
+	for _, id := range []uint{ownerID, strangerID, moderatorID} {
+		repos.Users.Put(models.User{ID: id, Email: "user@example.com", IsActive: true})
+	}
+
+	permissions := grants{moderatorID: {models.PermSamplesUpdateAny, models.PermSamplesDeleteAny}}
+	svc := NewSampleService(testConfig(), repos.Transactor, repos.Samples, repos.PendingDeletions, unreachableStorage{}, permissions, nil)
+	return svc, repos
+}
+
+func createSample(t *testing.T, svc *SampleService, title string) *models.Sample {
+	t.Helper()
+
+	sample, err := svc.CreateSample(ownerID, models.CreateSampleRequest{Title: title, Description: "first"}, nil)
+	if err != nil {
+		t.Fatalf("CreateSample(%q) error = %v", title, err)
+	}
+	return sample
+}
+
+func TestCreateSample(t *testing.T) {
+	svc, _ := newTestSampleService(t)
+
+	sample := createSample(t, svc, "Sunset")
+	if sample.ID == 0 || sample.UserID != ownerID || sample.User.ID != ownerID {
+		t.Errorf("CreateSample() = %+v, want a stored sample owned by user %d", sample, ownerID)
+	}
+
+	_, err := svc.CreateSample(strangerID, models.CreateSampleRequest{Title: "Sunset"}, nil)
+	if !errors.Is(err, ErrTitleTaken) {
+		t.Errorf("CreateSample(duplicate title) error = %v, want ErrTitleTaken", err)
+	}
+
+	samples, meta, err := svc.GetSamples(pagination.Params{Page: 1, PerPage: 10})
+	if err != nil || len(samples) != 1 || meta.Total != 1 {
+		t.Errorf("GetSamples() = %d samples, total %d, %v; want 1", len(samples), meta.Total, err)
+	}
+}
+
+func TestUpdateSample(t *testing.T) {
+	svc, _ := newTestSampleService(t)
+	sample := createSample(t, svc, "Sunset")
+
+	updated, err := svc.UpdateSample(ownerID, int(sample.ID), models.UpdateSampleRequest{Description: "second"}, nil)
+	if err != nil {
+		t.Fatalf("UpdateSample() by owner error = %v", err)
+	}
+	if updated.Title != "Sunset" || updated.Description != "second" {
+		t.Errorf("UpdateSample() = %q/%q, want the title kept and the description changed", updated.Title, updated.Description)
+	}
+
+	if _, err := svc.UpdateSample(strangerID, int(sample.ID), models.UpdateSampleRequest{Title: "Mine"}, nil); !errors.Is(err, ErrSampleForbidden) {
+		t.Errorf("UpdateSample() by another user error = %v, want ErrSampleForbidden", err)
+	}
+
+	updated, err = svc.UpdateSample(moderatorID, int(sample.ID), models.UpdateSampleRequest{Title: "Moderated"}, nil)
+	if err != nil || updated.Title != "Moderated" {
+		t.Errorf("UpdateSample() with %s = %v, %v; want the title changed", models.PermSamplesUpdateAny, updated, err)
+	}
+
+	if _, err := svc.UpdateSample(ownerID, 999, models.UpdateSampleRequest{Title: "Gone"}, nil); !errors.Is(err, ErrSampleNotFound) {
+		t.Errorf("UpdateSample(unknown) error = %v, want ErrSampleNotFound", err)
+	}
+}
+
+func TestDeleteSample(t *testing.T) {
+	svc, _ := newTestSampleService(t)
+	first := createSample(t, svc, "Sunset")
+	second := createSample(t, svc, "Sunrise")
+
+	if err := svc.DeleteSample(strangerID, int(first.ID)); !errors.Is(err, ErrSampleForbidden) {
+		t.Errorf("DeleteSample() by another user error = %v, want ErrSampleForbidden", err)
+	}
+
+	if err := svc.DeleteSample(ownerID, int(first.ID)); err != nil {
+		t.Fatalf("DeleteSample() by owner error = %v", err)
+	}
+	if _, err := svc.GetSampleById(int(first.ID)); !errors.Is(err, ErrSampleNotFound) {
+		t.Errorf("GetSampleById() after delete error = %v, want ErrSampleNotFound", err)
+	}
+
+	if err := svc.DeleteSample(moderatorID, int(second.ID)); err != nil {
+		t.Errorf("DeleteSample() with %s error = %v", models.PermSamplesDeleteAny, err)
+	}
+	if err := svc.DeleteSample(ownerID, int(second.ID)); !errors.Is(err, ErrSampleNotFound) {
+		t.Errorf("DeleteSample() twice error = %v, want ErrSampleNotFound", err)
+	}
+}
+
+func TestDeleteSampleQueuesFailedAssetDeletion(t *testing.T) {
+	svc, repos := newTestSampleService(t)
+	sample := createSample(t, svc, "Sunset")
+
+	ctx := context.Background()
+	if err := repos.Samples.CreateImage(ctx, &models.SampleImage{SampleID: sample.ID, PublicID: "samples/sunset"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The sample is deleted even though its image cannot be.
+	if err := svc.DeleteSample(ownerID, int(sample.ID)); err != nil {
+		t.Fatalf("DeleteSample() error = %v", err)
+	}
+
+	pending, ok := repos.PendingDeletions.Find("samples/sunset")
+	if !ok {
+		t.Fatal("failed deletion of the image was not queued")
+	}
+	if pending.Attempts != 1 || pending.LastError != errStorageDown.Error() || !pending.NextAttemptAt.After(pending.CreatedAt) {
+		t.Errorf("queued deletion = %+v, want one attempt retried later", pending)
+	}
+}
+
+func TestDetectImageType(t *testing.T) {
+	png := append([]byte("\x89PNG\r\n\x1a\n"), make([]byte, 32)...)
+	file := bytes.NewReader(png)
+	contentType, err := detectImageType(file)
+	if err != nil || contentType != "image/png" {
+		t.Fatalf("detectImageType(png) = %q, %v; want image/png", contentType, err)
+	}
+	if file.Len() != len(png) {
+		t.Errorf("detectImageType() left the file at %d, want it rewound", len(png)-file.Len())
+	}
+
+	for name, content := range map[string]string{
+		"gif":  "GIF89a\x01\x00\x01\x00",
+		"webp": "RIFF\x1a\x00\x00\x00WEBPVP8 ",
+		"text": "not an image",
+	} {
+		if _, err := detectImageType(bytes.NewReader([]byte(content))); !errors.Is(err, ErrInvalidImage) {
+			t.Errorf("detectImageType(%s) error = %v, want ErrInvalidImage", name, err)
+		}
+	}
+}
@@ -0,0 +1,260 @@
+package services
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"go-fiber-boilerplate/config"
+	"go-fiber-boilerplate/internal/models"
+	"go-fiber-boilerplate/internal/repositories"
+	"go-fiber-boilerplate/pkg/apperror"
+	"go-fiber-boilerplate/utils"
+)
+
+// UploadService stores tus resumable uploads. Metadata and the confirmed
+// offset live in the upload repository; the bytes received so far are kept in a
+// partial file per upload under TUS_UPLOAD_DIR on the local disk. Every
+// request for an upload must therefore reach the instance that created it:
+// run a single replica, or route /files with sticky sessions.
+type UploadService struct {
+	cfg     *config.Config
+	uploads repositories.UploadRepository
+}
+
+const uploadIDLength = 16
+
+// uploadLocks serialises PATCH requests for the same upload. It only covers
+// this process, which is enough because an upload's partial file is only
+// reachable from the instance that created it. The conditional offset update
+// in Append additionally rejects a request that lost a race.
+var uploadLocks sync.Map
+
+func NewUploadService(cfg *config.Config, uploads repositories.UploadRepository) *UploadService {
+	return &UploadService{cfg: cfg, uploads: uploads}
+}
+
+// Create registers a new upload of length bytes for the user.
+func (s *UploadService) Create(userID uint, length int64, filename, fileType string) (*models.ResumableUpload, error) {
+	if length <= 0 {
+		return nil, ErrUploadLengthRequired
+	}
+	if length > s.cfg.TusMaxSize {
+		return nil, ErrUploadTooLarge
+	}
+
+	id, err := utils.GenerateRandomToken(uploadIDLength)
+	if err != nil {
+		return nil, apperror.ErrInternal.Wrap(err)
+	}
+
+	if err := os.MkdirAll(s.cfg.TusUploadDir, 0o755); err != nil {
+		return nil, apperror.ErrInternal.Wrap(err)
+	}
+	file, err := os.OpenFile(s.partPath(id), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, apperror.ErrInternal.Wrap(err)
+	}
+	file.Close()
+
+	upload := models.ResumableUpload{
+		ID:        id,
+		UserID:    userID,
+		Filename:  filepath.Base(filename),
+		FileType:  fileType,
+		Length:    length,
+		ExpiresAt: time.Now().Add(s.cfg.TusUploadTTL),
+	}
+	if err := s.uploads.Create(context.Background(), &upload); err != nil {
+		os.Remove(s.partPath(id))
+		return nil, apperror.ErrInternal.Wrap(err)
+	}
+
+	return &upload, nil
+}
+
+// Get returns an unexpired upload owned by the user.
+func (s *UploadService) Get(userID uint, id string) (*models.ResumableUpload, error) {
+	if !validUploadID(id) {
+		return nil, ErrUploadNotFound
+	}
+
+	upload, err := s.uploads.FindActive(context.Background(), id, userID, time.Now())
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, ErrUploadNotFound
+		}
+		return nil, apperror.ErrInternal.Wrap(err)
+	}
+	return upload, nil
+}
+
+// Append writes a chunk that starts at offset. The offset must match what
+// has been received so far, and the chunk may not run past the declared
+// length.
+func (s *UploadService) Append(userID uint, id string, offset int64, chunk io.Reader) (*models.ResumableUpload, error) {
+	if !validUploadID(id) {
+		return nil, ErrUploadNotFound
+	}
+	unlock := lockUpload(id)
+	defer unlock()
+
+	upload, err := s.Get(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if offset != upload.Offset {
+		return nil, ErrUploadOffsetMismatch
+	}
+
+	file, err := os.OpenFile(s.partPath(id), os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, apperror.ErrInternal.Wrap(err)
+	}
+	defer file.Close()
+
+	// Drop bytes from an earlier request that failed before its offset was
+	// confirmed.
+	if err := file.Truncate(offset); err != nil {
+		return nil, apperror.ErrInternal.Wrap(err)
+	}
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		return nil, apperror.ErrInternal.Wrap(err)
+	}
+
+	remaining := upload.Length - offset
+	written, err := io.Copy(file, io.LimitReader(chunk, remaining+1))
+	if err != nil {
+		return nil, apperror.ErrInternal.Wrap(err)
+	}
+	if written > remaining {
+		return nil, ErrUploadExceedsLength
+	}
+
+	var completedAt *time.Time
+	if offset+written == upload.Length {
+		now := time.Now()
+		completedAt = &now
+	}
+	advanced, err := s.uploads.Advance(context.Background(), id, offset, offset+written, completedAt)
+	if err != nil {
+		return nil, apperror.ErrInternal.Wrap(err)
+	}
+	if !advanced {
+		return nil, ErrUploadOffsetMismatch
+	}
+
+	return s.Get(userID, id)
+}
+
+// Open returns the file of a completed upload owned by the user.
+func (s *UploadService) Open(userID uint, id string) (*os.File, *models.ResumableUpload, error) {
+	upload, err := s.Get(userID, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !upload.IsComplete() {
+		return nil, nil, ErrUploadIncomplete
+	}
+
+	file, err := os.Open(s.partPath(id))
+	if err != nil {
+		return nil, nil, apperror.ErrInternal.Wrap(err)
+	}
+	return file, upload, nil
+}
+
+// Delete removes an upload owned by the user together with its data.
+func (s *UploadService) Delete(userID uint, id string) error {
+	if !validUploadID(id) {
+		return ErrUploadNotFound
+	}
+	unlock := lockUpload(id)
+	defer unlock()
+
+	if _, err := s.Get(userID, id); err != nil {
+		return err
+	}
+	return s.remove(id)
+}
+
+// PurgeExpired removes every upload past its expiry together with its data
+// and returns how many were removed.
+func (s *UploadService) PurgeExpired() (int, error) {
+	ids, err := s.uploads.ExpiredIDs(context.Background(), time.Now())
+	if err != nil {
+		return 0, apperror.ErrInternal.Wrap(err)
+	}
+
+	purged := 0
+	for _, id := range ids {
+		unlock := lockUpload(id)
+		err := s.remove(id)
+		unlock()
+		if err != nil {
+			return purged, err
+		}
+		purged++
+	}
+	return purged, nil
+}
+
+// Start purges expired uploads every interval until ctx is cancelled. It runs
+// independently of the reconciliation job so abandoned partial files never
+// pile up on disk when that job is disabled.
+func (s *UploadService) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			purged, err := s.PurgeExpired()
+			if err != nil {
+				log.Printf("failed to purge expired uploads: %v", err)
+				continue
+			}
+			if purged > 0 {
+				log.Printf("purged %d expired uploads", purged)
+			}
+		}
+	}
+}
+
+func (s *UploadService) remove(id string) error {
+	if err := s.uploads.Delete(context.Background(), id); err != nil {
+		return apperror.ErrInternal.Wrap(err)
+	}
+	if err := os.Remove(s.partPath(id)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return apperror.ErrInternal.Wrap(fmt.Errorf("failed to remove upload data: %w", err))
+	}
+	uploadLocks.Delete(id)
+	return nil
+}
+
+func (s *UploadService) partPath(id string) string {
+	return filepath.Join(s.cfg.TusUploadDir, id+".part")
+}
+
+// validUploadID reports whether id has the shape Create generates, so other
+// input never reaches the filesystem or the lock table.
+func validUploadID(id string) bool {
+	decoded, err := hex.DecodeString(id)
+	return err == nil && len(decoded) == uploadIDLength
+}
+
+func lockUpload(id string) func() {
+	value, _ := uploadLocks.LoadOrStore(id, &sync.Mutex{})
+	mu := value.(*sync.Mutex)
+	mu.Lock()
+	return mu.Unlock
+}
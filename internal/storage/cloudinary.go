@@ -0,0 +1,166 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"go-fiber-boilerplate/config"
+
+	"github.com/cloudinary/cloudinary-go/v2"
+	"github.com/cloudinary/cloudinary-go/v2/api"
+	"github.com/cloudinary/cloudinary-go/v2/api/admin"
+	"github.com/cloudinary/cloudinary-go/v2/api/uploader"
+)
+
+// Cloudinary stores files in Cloudinary and serves variants through URL
+// transformations.
+type Cloudinary struct {
+	cld       *cloudinary.Cloudinary
+	cloudName string
+}
+
+var cloudinaryTransformations = map[string]string{
+	VariantThumbnail: "w_150,h_150,c_thumb,f_auto,q_auto",
+	VariantSmall:     "w_300,f_auto,q_auto",
+	VariantMedium:    "w_600,f_auto,q_auto",
+	VariantLarge:     "w_1200,f_auto,q_auto",
+	VariantOriginal:  "f_auto,q_auto",
+}
+
+// cloudinaryWidths are the widths cloudinaryTransformations scale to; the
+// height keeps the aspect ratio. The thumbnail is cropped to 150x150 and the
+// original is served as stored.
+var cloudinaryWidths = map[string]int{
+	VariantSmall:  300,
+	VariantMedium: 600,
+	VariantLarge:  1200,
+}
+
+func NewCloudinary(cfg *config.Config) (*Cloudinary, error) {
+	if cfg.CloudinaryCloudName == "" || cfg.CloudinaryAPIKey == "" || cfg.CloudinaryAPISecret == "" {
+		return nil, fmt.Errorf("cloudinary credentials are required")
+	}
+
+	cld, err := cloudinary.NewFromParams(
+		cfg.CloudinaryCloudName,
+		cfg.CloudinaryAPIKey,
+		cfg.CloudinaryAPISecret,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize cloudinary: %w", err)
+	}
+
+	return &Cloudinary{
+		cld:       cld,
+		cloudName: cfg.CloudinaryCloudName,
+	}, nil
+}
+
+func (s *Cloudinary) Put(ctx context.Context, body io.Reader, in PutInput) (*Object, error) {
+	// Cloudinary tracks the format itself, so the public ID has no extension.
+	key := objectKey("", in.Filename)
+	publicID := key[:len(key)-len(path.Ext(key))]
+
+	overwrite := true
+	result, err := s.cld.Upload.Upload(ctx, body, uploader.UploadParams{
+		PublicID:       publicID,
+		Folder:         in.Folder,
+		Transformation: "f_auto,q_auto,w_1200",
+		Overwrite:      &overwrite,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to upload to cloudinary: %w", err)
+	}
+	if result.Error.Message != "" {
+		return nil, fmt.Errorf("failed to upload to cloudinary: %s", result.Error.Message)
+	}
+
+	return &Object{
+		PublicID: result.PublicID,
+		URL:      result.SecureURL,
+		Format:   result.Format,
+		Width:    result.Width,
+		Height:   result.Height,
+		Bytes:    int64(result.Bytes),
+		Variants: s.variantList(result.PublicID, result.Width, result.Height),
+	}, nil
+}
+
+// variantList returns the variants of an image stored at width x height,
+// with the dimensions its transformations produce.
+func (s *Cloudinary) variantList(publicID string, width, height int) []Variant {
+	list := make([]Variant, 0, len(VariantNames))
+	for _, name := range VariantNames {
+		variant := Variant{Name: name, URL: s.URL(publicID, name), Width: width, Height: height}
+		switch {
+		case name == VariantThumbnail:
+			variant.Width, variant.Height = 150, 150
+		case cloudinaryWidths[name] > 0 && width > 0:
+			variant.Width = cloudinaryWidths[name]
+			variant.Height = (height*variant.Width + width/2) / width
+		}
+		list = append(list, variant)
+	}
+	return list
+}
+
+func (s *Cloudinary) Delete(ctx context.Context, publicID string) error {
+	_, err := s.cld.Upload.Destroy(ctx, uploader.DestroyParams{
+		PublicID: publicID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete image from cloudinary: %w", err)
+	}
+	return nil
+}
+
+func (s *Cloudinary) URL(publicID, variant string) string {
+	transform, exists := cloudinaryTransformations[variant]
+	if !exists {
+		transform = cloudinaryTransformations[VariantOriginal]
+	}
+
+	baseURL := fmt.Sprintf("https://res.cloudinary.com/%s/image/upload", s.cloudName)
+	return fmt.Sprintf("%s/%s/%s", baseURL, transform, publicID)
+}
+
+func (s *Cloudinary) Variants(publicID string) map[string]string {
+	return variants(s, publicID)
+}
+
+func (s *Cloudinary) List(ctx context.Context, folder string, fn func(Asset) error) error {
+	prefix := strings.Trim(folder, "/")
+	if prefix != "" {
+		prefix += "/"
+	}
+
+	params := admin.AssetsParams{
+		AssetType:    api.Image,
+		DeliveryType: "upload",
+		Prefix:       prefix,
+		MaxResults:   500,
+	}
+	for {
+		result, err := s.cld.Admin.Assets(ctx, params)
+		if err != nil {
+			return fmt.Errorf("failed to list cloudinary assets: %w", err)
+		}
+		if result.Error.Message != "" {
+			return fmt.Errorf("failed to list cloudinary assets: %s", result.Error.Message)
+		}
+
+		for _, asset := range result.Assets {
+			if err := fn(Asset{PublicID: asset.PublicID, CreatedAt: asset.CreatedAt}); err != nil {
+				return err
+			}
+		}
+
+		if result.NextCursor == "" {
+			return nil
+		}
+		params.NextCursor = result.NextCursor
+	}
+}
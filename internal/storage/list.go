@@ -0,0 +1,78 @@
+package storage
+
+import (
+	"context"
+	"path"
+	"strings"
+	"time"
+)
+
+// Asset is a stored file reported by List, identified by the public ID
+// Put returned for it. Variant files are folded into their original.
+type Asset struct {
+	PublicID  string
+	CreatedAt time.Time
+}
+
+// Lister is implemented by backends that can enumerate their files. The
+// orphaned asset reconciliation uses it to compare storage with the
+// database; all built-in backends implement it.
+type Lister interface {
+	// List calls fn for every asset under folder. Returning an error from fn
+	// stops the listing and is returned by List.
+	List(ctx context.Context, folder string, fn func(Asset) error) error
+}
+
+// listAssets lists the keys of an objectStore under folder and reports each
+// public ID once, with variant keys mapped back to the original.
+func listAssets(ctx context.Context, store objectStore, folder string, fn func(Asset) error) error {
+	assets := make(map[string]time.Time)
+	var order []string
+
+	prefix := strings.Trim(folder, "/")
+	if prefix != "" {
+		prefix += "/"
+	}
+	if err := store.listObjects(ctx, prefix, func(key string, modified time.Time) error {
+		publicID := originalKey(key)
+		if seen, ok := assets[publicID]; !ok {
+			assets[publicID] = modified
+			order = append(order, publicID)
+		} else if modified.Before(seen) {
+			assets[publicID] = modified
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	for _, publicID := range order {
+		if err := fn(Asset{PublicID: publicID, CreatedAt: assets[publicID]}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// originalKey is the inverse of variantKey: "samples/photo_1_thumbnail.jpg"
+// -> "samples/photo_1.jpg". Other keys are returned unchanged.
+func originalKey(key string) string {
+	ext := path.Ext(key)
+	if ext != ".jpg" && ext != ".png" {
+		return key
+	}
+
+	base := strings.TrimSuffix(key, ext)
+	for _, variant := range []string{VariantThumbnail, VariantSmall, VariantMedium} {
+		if original, ok := strings.CutSuffix(base, "_"+variant); ok {
+			return original + ext
+		}
+	}
+	return key
+}
+
+var (
+	_ Lister = (*Cloudinary)(nil)
+	_ Lister = (*Local)(nil)
+	_ Lister = (*S3)(nil)
+)
@@ -0,0 +1,150 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Local stores files on the local filesystem under root. The files are
+// expected to be served at baseURL, e.g. by app.Static.
+type Local struct {
+	root    string
+	baseURL string
+}
+
+func NewLocal(root, baseURL string) (*Local, error) {
+	if root == "" {
+		return nil, fmt.Errorf("local storage directory is required")
+	}
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create storage directory: %w", err)
+	}
+
+	return &Local{
+		root:    root,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+	}, nil
+}
+
+func (s *Local) Put(ctx context.Context, body io.Reader, in PutInput) (*Object, error) {
+	return putObjects(ctx, s, body, in)
+}
+
+func (s *Local) Delete(ctx context.Context, publicID string) error {
+	return deleteObjects(ctx, s, publicID)
+}
+
+func (s *Local) URL(publicID, variant string) string {
+	return objectVariantURL(s, publicID, variant)
+}
+
+func (s *Local) Variants(publicID string) map[string]string {
+	return variants(s, publicID)
+}
+
+func (s *Local) List(ctx context.Context, folder string, fn func(Asset) error) error {
+	return listAssets(ctx, s, folder, fn)
+}
+
+func (s *Local) putObject(ctx context.Context, key string, body io.Reader, size int64, contentType string) (int64, error) {
+	dest, err := s.path(key)
+	if err != nil {
+		return 0, err
+	}
+	if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+		return 0, fmt.Errorf("failed to create storage directory: %w", err)
+	}
+	return writeFile(dest, body)
+}
+
+func (s *Local) deleteObject(ctx context.Context, key string) error {
+	file, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(file); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to delete file: %w", err)
+	}
+	return nil
+}
+
+func (s *Local) objectURL(key string) string {
+	return s.baseURL + "/" + key
+}
+
+func (s *Local) listObjects(ctx context.Context, prefix string, fn func(key string, modified time.Time) error) error {
+	dir := filepath.Join(s.root, filepath.FromSlash(prefix))
+	err := filepath.WalkDir(dir, func(file string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		// Skip directories and the temporary files of writes in progress.
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(s.root, file)
+		if err != nil {
+			return err
+		}
+		return fn(filepath.ToSlash(rel), info.ModTime())
+	})
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to list files: %w", err)
+	}
+	return nil
+}
+
+// path maps a public ID to a file under root, rejecting IDs that would
+// escape it.
+func (s *Local) path(publicID string) (string, error) {
+	clean := path.Clean("/" + publicID)
+	if clean == "/" || clean != "/"+publicID {
+		return "", fmt.Errorf("invalid public id %q", publicID)
+	}
+	return filepath.Join(s.root, filepath.FromSlash(clean)), nil
+}
+
+// writeFile writes body to dest through a temporary file so readers never
+// see a partial upload.
+func writeFile(dest string, body io.Reader) (int64, error) {
+	tmp, err := os.CreateTemp(filepath.Dir(dest), ".upload-*")
+	if err != nil {
+		return 0, fmt.Errorf("failed to create file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := tmp.Chmod(0o644); err != nil {
+		tmp.Close()
+		return 0, fmt.Errorf("failed to create file: %w", err)
+	}
+	written, err := io.Copy(tmp, body)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to write file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), dest); err != nil {
+		return 0, fmt.Errorf("failed to store file: %w", err)
+	}
+	return written, nil
+}
@@ -0,0 +1,160 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"go-fiber-boilerplate/internal/imageproc"
+)
+
+// objectStore is the plain key/value API of Local and S3. Both build Storage
+// on top of it with putObjects and deleteObjects, which run JPEG and PNG
+// uploads through the in-process image pipeline.
+type objectStore interface {
+	putObject(ctx context.Context, key string, body io.Reader, size int64, contentType string) (int64, error)
+	deleteObject(ctx context.Context, key string) error
+	objectURL(key string) string
+	listObjects(ctx context.Context, prefix string, fn func(key string, modified time.Time) error) error
+}
+
+// imageSpecs mirror the Cloudinary transformations. The stored original is
+// capped at 1200px wide like the Cloudinary upload, so it doubles as the
+// large variant.
+var imageSpecs = []imageproc.Spec{
+	{Name: VariantOriginal, Width: 1200},
+	{Name: VariantThumbnail, Width: 150, Height: 150, Crop: true},
+	{Name: VariantSmall, Width: 300},
+	{Name: VariantMedium, Width: 600},
+}
+
+// ErrUnsupportedImage is returned by Put for JPEG or PNG uploads that cannot
+// be decoded.
+var ErrUnsupportedImage = errors.New("unsupported or corrupt image")
+
+// ErrImageTooLarge is returned by Put for images whose pixel dimensions are
+// too large to decode safely.
+var ErrImageTooLarge = errors.New("image dimensions are too large")
+
+func putObjects(ctx context.Context, store objectStore, body io.Reader, in PutInput) (*Object, error) {
+	buffered := bufio.NewReader(body)
+	head, _ := buffered.Peek(512)
+	contentType := http.DetectContentType(head)
+
+	key := objectKey(in.Folder, in.Filename)
+
+	if contentType != "image/jpeg" && contentType != "image/png" {
+		// Never let unprocessed content claim an extension that variantKey
+		// treats as having variants.
+		if ext := path.Ext(key); ext == ".jpg" || ext == ".jpeg" || ext == ".png" {
+			key = strings.TrimSuffix(key, ext) + ".bin"
+		}
+		written, err := store.putObject(ctx, key, buffered, in.Size, in.ContentType)
+		if err != nil {
+			return nil, err
+		}
+		return &Object{
+			PublicID: key,
+			URL:      store.objectURL(key),
+			Format:   strings.TrimPrefix(path.Ext(key), "."),
+			Bytes:    written,
+		}, nil
+	}
+
+	result, err := imageproc.Process(buffered, imageSpecs)
+	if err != nil {
+		if errors.Is(err, imageproc.ErrUnsupported) {
+			return nil, ErrUnsupportedImage
+		}
+		if errors.Is(err, imageproc.ErrTooLarge) {
+			return nil, ErrImageTooLarge
+		}
+		return nil, err
+	}
+
+	// The key extension follows the decoded format, not the client filename.
+	publicID := strings.TrimSuffix(key, path.Ext(key)) + result.Extension()
+	object := &Object{
+		PublicID: publicID,
+		URL:      store.objectURL(publicID),
+		Format:   result.Format,
+	}
+
+	var written []string
+	stored := make(map[string]Variant, len(result.Renditions))
+	for _, rendition := range result.Renditions {
+		variantID := variantKey(publicID, rendition.Name)
+		if _, err := store.putObject(ctx, variantID, bytes.NewReader(rendition.Data), int64(len(rendition.Data)), result.ContentType()); err != nil {
+			for _, key := range written {
+				store.deleteObject(ctx, key)
+			}
+			return nil, fmt.Errorf("failed to store %s variant: %w", rendition.Name, err)
+		}
+		written = append(written, variantID)
+
+		if rendition.Name == VariantOriginal {
+			object.Width = rendition.Width
+			object.Height = rendition.Height
+			object.Bytes = int64(len(rendition.Data))
+		}
+		stored[rendition.Name] = Variant{
+			Name:   rendition.Name,
+			URL:    store.objectURL(variantID),
+			Width:  rendition.Width,
+			Height: rendition.Height,
+		}
+	}
+
+	// The original doubles as the large variant, see imageSpecs.
+	large := stored[VariantOriginal]
+	large.Name = VariantLarge
+	stored[VariantLarge] = large
+	for _, name := range VariantNames {
+		object.Variants = append(object.Variants, stored[name])
+	}
+
+	return object, nil
+}
+
+func deleteObjects(ctx context.Context, store objectStore, publicID string) error {
+	var errs []error
+	for _, name := range VariantNames {
+		if key := variantKey(publicID, name); key != publicID {
+			if err := store.deleteObject(ctx, key); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	if err := store.deleteObject(ctx, publicID); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+func objectVariantURL(store objectStore, publicID, variant string) string {
+	return store.objectURL(variantKey(publicID, variant))
+}
+
+// variantKey returns the key a variant of publicID is stored under, e.g.
+// "samples/photo_1.jpg" -> "samples/photo_1_thumbnail.jpg". Files the
+// pipeline did not process only have the original.
+func variantKey(publicID, variant string) string {
+	ext := path.Ext(publicID)
+	if ext != ".jpg" && ext != ".png" {
+		return publicID
+	}
+
+	switch variant {
+	case VariantThumbnail, VariantSmall, VariantMedium:
+		return strings.TrimSuffix(publicID, ext) + "_" + variant + ext
+	default:
+		return publicID
+	}
+}
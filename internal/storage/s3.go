@@ -0,0 +1,124 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"go-fiber-boilerplate/config"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// S3 stores files in an S3-compatible bucket such as AWS S3 or MinIO.
+// Objects are served from cfg.StoragePublicURL when set, or directly from
+// the bucket with path-style URLs otherwise.
+type S3 struct {
+	client  *minio.Client
+	bucket  string
+	baseURL string
+}
+
+func NewS3(cfg *config.Config) (*S3, error) {
+	client, err := minio.New(cfg.S3Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(cfg.S3AccessKey, cfg.S3SecretKey, ""),
+		Secure: cfg.S3UseSSL,
+		Region: cfg.S3Region,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize s3 client: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	exists, err := client.BucketExists(ctx, cfg.S3Bucket)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach s3 bucket %s: %w", cfg.S3Bucket, err)
+	}
+	if !exists {
+		return nil, fmt.Errorf("s3 bucket %s does not exist", cfg.S3Bucket)
+	}
+
+	baseURL := cfg.StoragePublicURL
+	if baseURL == "" {
+		scheme := "http"
+		if cfg.S3UseSSL {
+			scheme = "https"
+		}
+		baseURL = fmt.Sprintf("%s://%s/%s", scheme, cfg.S3Endpoint, cfg.S3Bucket)
+	}
+
+	return &S3{
+		client:  client,
+		bucket:  cfg.S3Bucket,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+	}, nil
+}
+
+func (s *S3) Put(ctx context.Context, body io.Reader, in PutInput) (*Object, error) {
+	return putObjects(ctx, s, body, in)
+}
+
+func (s *S3) Delete(ctx context.Context, publicID string) error {
+	return deleteObjects(ctx, s, publicID)
+}
+
+func (s *S3) URL(publicID, variant string) string {
+	return objectVariantURL(s, publicID, variant)
+}
+
+func (s *S3) Variants(publicID string) map[string]string {
+	return variants(s, publicID)
+}
+
+func (s *S3) List(ctx context.Context, folder string, fn func(Asset) error) error {
+	return listAssets(ctx, s, folder, fn)
+}
+
+func (s *S3) putObject(ctx context.Context, key string, body io.Reader, size int64, contentType string) (int64, error) {
+	if size <= 0 {
+		size = -1
+	}
+
+	info, err := s.client.PutObject(ctx, s.bucket, key, body, size, minio.PutObjectOptions{
+		ContentType: contentType,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to upload to s3: %w", err)
+	}
+	return info.Size, nil
+}
+
+func (s *S3) deleteObject(ctx context.Context, key string) error {
+	if err := s.client.RemoveObject(ctx, s.bucket, key, minio.RemoveObjectOptions{}); err != nil {
+		return fmt.Errorf("failed to delete object from s3: %w", err)
+	}
+	return nil
+}
+
+func (s *S3) objectURL(key string) string {
+	return s.baseURL + "/" + key
+}
+
+func (s *S3) listObjects(ctx context.Context, prefix string, fn func(key string, modified time.Time) error) error {
+	// Cancelling stops the listing goroutine when fn returns early.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	for object := range s.client.ListObjects(ctx, s.bucket, minio.ListObjectsOptions{
+		Prefix:    prefix,
+		Recursive: true,
+	}) {
+		if object.Err != nil {
+			return fmt.Errorf("failed to list s3 objects: %w", object.Err)
+		}
+		if err := fn(object.Key, object.LastModified); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"go-fiber-boilerplate/config"
+)
+
+// Image variants every backend can serve. VariantOriginal is the stored file
+// itself; the others are resized renditions.
+const (
+	VariantThumbnail = "thumbnail"
+	VariantSmall     = "small"
+	VariantMedium    = "medium"
+	VariantLarge     = "large"
+	VariantOriginal  = "original"
+)
+
+// VariantNames lists the variants in the order they are reported.
+var VariantNames = []string{VariantThumbnail, VariantSmall, VariantMedium, VariantLarge, VariantOriginal}
+
+// Storage stores uploaded files and builds public URLs for them. A file is
+// addressed by the public ID returned from Put, which is what gets persisted
+// (e.g. Sample.ImagePublicID).
+type Storage interface {
+	// Put stores body under a new public ID derived from in.Folder and
+	// in.Filename.
+	Put(ctx context.Context, body io.Reader, in PutInput) (*Object, error)
+	// Delete removes the file and any variants. Deleting a file that does not
+	// exist is not an error.
+	Delete(ctx context.Context, publicID string) error
+	// URL returns the public URL of a variant, falling back to the original
+	// for unknown variant names.
+	URL(publicID, variant string) string
+	// Variants returns the URL of every variant keyed by name.
+	Variants(publicID string) map[string]string
+}
+
+// PutInput describes a file being stored.
+type PutInput struct {
+	Folder      string
+	Filename    string
+	ContentType string
+	Size        int64
+}
+
+// Object describes a stored file. For images Variants lists every variant in
+// VariantNames with its URL and dimensions; it is empty for other files.
+type Object struct {
+	PublicID string    `json:"public_id"`
+	URL      string    `json:"url"`
+	Format   string    `json:"format"`
+	Width    int       `json:"width"`
+	Height   int       `json:"height"`
+	Bytes    int64     `json:"bytes"`
+	Variants []Variant `json:"variants,omitempty"`
+}
+
+// Variant is a stored rendition of an image and its dimensions.
+type Variant struct {
+	Name   string `json:"name"`
+	URL    string `json:"url"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+}
+
+// New returns the backend selected by cfg.StorageBackend.
+func New(cfg *config.Config) (Storage, error) {
+	switch cfg.StorageBackend {
+	case config.StorageCloudinary:
+		return NewCloudinary(cfg)
+	case config.StorageLocal:
+		return NewLocal(cfg.StorageLocalDir, cfg.StoragePublicURL)
+	case config.StorageS3:
+		return NewS3(cfg)
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q", cfg.StorageBackend)
+	}
+}
+
+// variants builds the variant map of s from its URL method.
+func variants(s Storage, publicID string) map[string]string {
+	urls := make(map[string]string, len(VariantNames))
+	for _, name := range VariantNames {
+		urls[name] = s.URL(publicID, name)
+	}
+	return urls
+}
+
+var (
+	unsafeNameChars = regexp.MustCompile(`[^a-zA-Z0-9_-]+`)
+	safeExtension   = regexp.MustCompile(`^\.[a-z0-9]+$`)
+)
+
+// objectKey builds a unique, path-safe key such as
+// "samples/holiday_photo_1700000000000000000.jpg".
+func objectKey(folder, filename string) string {
+	ext := strings.ToLower(filepath.Ext(filename))
+	base := strings.TrimSuffix(filepath.Base(filename), filepath.Ext(filename))
+	base = strings.Trim(unsafeNameChars.ReplaceAllString(base, "_"), "_")
+	if base == "" {
+		base = "file"
+	}
+	if !safeExtension.MatchString(ext) {
+		ext = ""
+	}
+
+	name := fmt.Sprintf("%s_%d%s", base, time.Now().UnixNano(), ext)
+	if folder == "" {
+		return name
+	}
+	return path.Join(folder, name)
+}
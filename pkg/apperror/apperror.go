@@ -0,0 +1,120 @@
+package apperror
+
+import (
+	"errors"
+	"net/http"
+)
+
+// Error is an error that knows how it should be presented to API clients.
+// Code is a stable machine-readable identifier, Status the HTTP status and
+// Message a safe public message. Fields optionally lists per-field problems,
+// e.g. from request validation. The wrapped cause is only ever logged.
+type Error struct {
+	Code    string
+	Status  int
+	Message string
+	Fields  []FieldError
+	Err     error
+}
+
+// FieldError describes why a single request field was rejected.
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+func New(code string, status int, message string) *Error {
+	return &Error{
+		Code:    code,
+		Status:  status,
+		Message: message,
+	}
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is matches any *Error with the same code, so errors.Is works against the
+// sentinels regardless of the wrapped cause or overridden message.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// Wrap returns a copy of e carrying err as its cause.
+func (e *Error) Wrap(err error) *Error {
+	clone := *e
+	clone.Err = err
+	return &clone
+}
+
+// WithMessage returns a copy of e with a different public message.
+func (e *Error) WithMessage(message string) *Error {
+	clone := *e
+	clone.Message = message
+	return &clone
+}
+
+// WithStatus returns a copy of e with a different HTTP status.
+func (e *Error) WithStatus(status int) *Error {
+	clone := *e
+	clone.Status = status
+	return &clone
+}
+
+// WithFields returns a copy of e carrying per-field error details.
+func (e *Error) WithFields(fields []FieldError) *Error {
+	clone := *e
+	clone.Fields = fields
+	return &clone
+}
+
+// As returns the *Error in err's chain, if any.
+func As(err error) (*Error, bool) {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr, true
+	}
+	return nil, false
+}
+
+// FromStatus builds an Error for a bare HTTP status, e.g. from a fiber.Error.
+func FromStatus(status int, message string) *Error {
+	for _, e := range []*Error{ErrBadRequest, ErrUnauthorized, ErrForbidden, ErrNotFound, ErrConflict, ErrTooManyRequests, ErrInternal} {
+		if e.Status == status {
+			if message == "" || status >= http.StatusInternalServerError {
+				return e
+			}
+			return e.WithMessage(message)
+		}
+	}
+
+	if status >= http.StatusInternalServerError {
+		return ErrInternal.WithStatus(status)
+	}
+	if message == "" {
+		message = http.StatusText(status)
+	}
+	return New("http_error", status, message)
+}
+
+var (
+	ErrBadRequest      = New("bad_request", http.StatusBadRequest, "Bad request")
+	ErrInvalidBody     = New("invalid_body", http.StatusBadRequest, "Invalid request body")
+	ErrValidation      = New("validation_failed", http.StatusBadRequest, "Request validation failed")
+	ErrUnauthorized    = New("unauthorized", http.StatusUnauthorized, "Unauthorized")
+	ErrForbidden       = New("forbidden", http.StatusForbidden, "Forbidden")
+	ErrNotFound        = New("not_found", http.StatusNotFound, "Resource not found")
+	ErrConflict        = New("conflict", http.StatusConflict, "Conflict")
+	ErrTooManyRequests = New("too_many_requests", http.StatusTooManyRequests, "Too many requests")
+	ErrInternal        = New("internal_error", http.StatusInternalServerError, "Internal server error")
+)
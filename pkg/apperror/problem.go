@@ -0,0 +1,37 @@
+package apperror
+
+import "net/http"
+
+// ProblemContentType is the media type of RFC 7807 problem details.
+const ProblemContentType = "application/problem+json"
+
+// ProblemTypePrefix prefixes the error code to form the problem "type" URI.
+const ProblemTypePrefix = "urn:problem-type:"
+
+// Problem is an RFC 7807 problem details object. Code, RequestID and Errors
+// are extension members so clients can branch on a stable code, quote the
+// request ID when reporting an issue and highlight invalid fields.
+type Problem struct {
+	Type      string       `json:"type"`
+	Title     string       `json:"title"`
+	Status    int          `json:"status"`
+	Detail    string       `json:"detail,omitempty"`
+	Instance  string       `json:"instance,omitempty"`
+	Code      string       `json:"code"`
+	RequestID string       `json:"request_id,omitempty"`
+	Errors    []FieldError `json:"errors,omitempty"`
+}
+
+// Problem describes e as problem details for the given request path and ID.
+func (e *Error) Problem(instance, requestID string) Problem {
+	return Problem{
+		Type:      ProblemTypePrefix + e.Code,
+		Title:     http.StatusText(e.Status),
+		Status:    e.Status,
+		Detail:    e.Message,
+		Instance:  instance,
+		Code:      e.Code,
+		RequestID: requestID,
+		Errors:    e.Fields,
+	}
+}
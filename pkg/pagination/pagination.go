@@ -19,6 +19,8 @@ type Params struct {
 	SortBy    string
 	SortOrder SortOrder
 	All       bool
+	Search    string
+	Filters   map[string]string
 }
 
 type Meta struct {
@@ -31,6 +33,17 @@ type Meta struct {
 
 const maxPerPage = 50
 
+// reservedKeys are query parameters consumed by Params itself; every other
+// non-empty parameter is exposed through Filters.
+var reservedKeys = map[string]bool{
+	"page":      true,
+	"perPage":   true,
+	"sortBy":    true,
+	"sortOrder": true,
+	"search":    true,
+	"all":       true,
+}
+
 func NewParams(q map[string]string) Params {
 	toInt := func(s string, def int) int {
 		if v, err := strconv.Atoi(s); err == nil && v > 0 {
@@ -54,13 +67,53 @@ func NewParams(q map[string]string) Params {
 	}
 	all := false // explicit disable to prevent unbounded fetches
 
+	filters := make(map[string]string)
+	for key, value := range q {
+		value = strings.TrimSpace(value)
+		if !reservedKeys[key] && value != "" {
+			filters[key] = value
+		}
+	}
+
 	return Params{
 		Page:      page,
 		PerPage:   per,
 		SortBy:    sortBy,
 		SortOrder: order,
 		All:       all,
+		Search:    strings.TrimSpace(q["search"]),
+		Filters:   filters,
+	}
+}
+
+// Filter returns the value of a filter query parameter, if present.
+func (p Params) Filter(key string) (string, bool) {
+	value, ok := p.Filters[key]
+	return value, ok
+}
+
+// BoolFilter parses a true/false filter. ok is false when the filter is
+// missing or not a valid boolean.
+func (p Params) BoolFilter(key string) (value bool, ok bool) {
+	raw, exists := p.Filters[key]
+	if !exists {
+		return false, false
+	}
+	parsed, err := strconv.ParseBool(raw)
+	if err != nil {
+		return false, false
 	}
+	return parsed, true
+}
+
+// likeEscaper escapes the characters LIKE treats specially.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// SearchPattern turns Search into a lower-case LIKE pattern matching it
+// anywhere, with the wildcards it contains escaped so they match literally.
+// Use it with ESCAPE '\'.
+func (p Params) SearchPattern() string {
+	return "%" + likeEscaper.Replace(strings.ToLower(p.Search)) + "%"
 }
 
 func (p Params) Offset() int {
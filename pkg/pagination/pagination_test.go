@@ -0,0 +1,20 @@
+package pagination
+
+import "testing"
+
+func TestSearchPattern(t *testing.T) {
+	tests := []struct {
+		search string
+		want   string
+	}{
+		{"Alice", "%alice%"},
+		{"100%", `%100\%%`},
+		{"first_name", `%first\_name%`},
+		{`a\b`, `%a\\b%`},
+	}
+	for _, tt := range tests {
+		if got := (Params{Search: tt.search}).SearchPattern(); got != tt.want {
+			t.Errorf("SearchPattern(%q) = %q, want %q", tt.search, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package validation
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+
+	"go-fiber-boilerplate/pkg/apperror"
+	"go-fiber-boilerplate/utils"
+
+	"github.com/go-playground/validator/v10"
+)
+
+var validate = newValidator()
+
+func newValidator() *validator.Validate {
+	v := validator.New(validator.WithRequiredStructEnabled())
+
+	// Report fields by the name clients send: the json tag, or the form tag
+	// for multipart-only fields.
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		for _, tag := range []string{"json", "form"} {
+			name := strings.SplitN(field.Tag.Get(tag), ",", 2)[0]
+			if name == "-" {
+				return ""
+			}
+			if name != "" {
+				return name
+			}
+		}
+		return field.Name
+	})
+
+	// Keep the tag rules in line with the checks used elsewhere in the app.
+	mustRegister(v, "email", func(fl validator.FieldLevel) bool {
+		return utils.ValidateEmail(fl.Field().String())
+	})
+	mustRegister(v, "password", func(fl validator.FieldLevel) bool {
+		return utils.ValidatePassword(fl.Field().String())
+	})
+	mustRegister(v, "notblank", func(fl validator.FieldLevel) bool {
+		return strings.TrimSpace(fl.Field().String()) != ""
+	})
+
+	return v
+}
+
+func mustRegister(v *validator.Validate, tag string, fn validator.Func) {
+	if err := v.RegisterValidation(tag, fn); err != nil {
+		panic(err)
+	}
+}
+
+// Struct checks s against its `validate` tags. Violations are returned as
+// apperror.ErrValidation with one FieldError per invalid field.
+func Struct(s interface{}) error {
+	err := validate.Struct(s)
+	if err == nil {
+		return nil
+	}
+
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		return apperror.ErrInternal.Wrap(err)
+	}
+
+	fields := make([]apperror.FieldError, 0, len(validationErrs))
+	for _, fe := range validationErrs {
+		fields = append(fields, apperror.FieldError{
+			Field:   fieldPath(fe),
+			Rule:    fe.Tag(),
+			Message: message(fe),
+		})
+	}
+	return apperror.ErrValidation.WithFields(fields)
+}
+
+// fieldPath drops the top-level struct name from the namespace, so a nested
+// field is reported as "address.city" rather than "Request.address.city".
+func fieldPath(fe validator.FieldError) string {
+	ns := fe.Namespace()
+	if i := strings.Index(ns, "."); i >= 0 {
+		return ns[i+1:]
+	}
+	return fe.Field()
+}
+
+func message(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required", "notblank":
+		return "is required"
+	case "email":
+		return "must be a valid email address"
+	case "password":
+		return "must include upper, lower, number, special and be at least 8 characters"
+	case "min":
+		if fe.Kind() == reflect.Slice || fe.Kind() == reflect.Map {
+			return "must contain at least " + fe.Param() + " items"
+		}
+		return "must be at least " + fe.Param() + " characters"
+	case "max":
+		if fe.Kind() == reflect.Slice || fe.Kind() == reflect.Map {
+			return "must contain at most " + fe.Param() + " items"
+		}
+		return "must be at most " + fe.Param() + " characters"
+	case "len":
+		return "must be exactly " + fe.Param() + " characters"
+	case "oneof":
+		return "must be one of: " + fe.Param()
+	case "numeric":
+		return "must be numeric"
+	case "url":
+		return "must be a valid URL"
+	default:
+		return "is invalid"
+	}
+}
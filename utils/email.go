@@ -113,6 +113,34 @@ func GeneratePasswordResetSuccessEmail() string {
 	`
 }
 
+func GenerateVerifyEmail(verifyLink string) string {
+	return fmt.Sprintf(`
+		<html>
+		<body>
+			<h2>Verify Your Email</h2>
+			<p>Thanks for signing up. Click the link below to verify your email address:</p>
+			<p><a href="%s" style="background-color: #4CAF50; color: white; padding: 10px 20px; text-decoration: none; border-radius: 5px;">Verify Email</a></p>
+			<p>If you did not create an account, please ignore this email.</p>
+			<p>This link will expire in 24 hours.</p>
+		</body>
+		</html>
+	`, verifyLink)
+}
+
+func GenerateUnlockAccountEmail(unlockLink string) string {
+	return fmt.Sprintf(`
+		<html>
+		<body>
+			<h2>Your Account Has Been Locked</h2>
+			<p>We locked your account temporarily after several failed sign-in attempts. Click the link below to unlock it now:</p>
+			<p><a href="%s" style="background-color: #4CAF50; color: white; padding: 10px 20px; text-decoration: none; border-radius: 5px;">Unlock Account</a></p>
+			<p>If these attempts were not yours, consider changing your password after unlocking.</p>
+			<p>This link will expire in 24 hours.</p>
+		</body>
+		</html>
+	`, unlockLink)
+}
+
 func ValidateEmail(email string) bool {
 	if strings.ContainsAny(email, "\r\n") {
 		return false
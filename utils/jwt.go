@@ -8,18 +8,46 @@ import (
 )
 
 type Claims struct {
-	UserID uint   `json:"user_id"`
-	Email  string `json:"email"`
+	UserID       uint   `json:"user_id"`
+	Email        string `json:"email"`
+	TokenVersion int    `json:"ver"`
+	Purpose      string `json:"purpose,omitempty"`
 	jwt.RegisteredClaims
 }
 
-func GenerateJWT(userID uint, email, secret, issuer, audience string) (string, error) {
+const (
+	jtiLength = 16
+
+	// PurposeMFAPending marks a token that only proves the password step of a
+	// login and can solely be exchanged for an access token via MFA verification.
+	PurposeMFAPending = "mfa_pending"
+)
+
+func GenerateJWT(userID uint, email string, tokenVersion int, secret, issuer, audience string, ttl time.Duration) (string, error) {
+	return generateJWT(userID, email, tokenVersion, "", secret, issuer, audience, ttl)
+}
+
+// GenerateMFAToken issues a short-lived token for a login that still has to pass the second factor.
+func GenerateMFAToken(userID uint, email string, tokenVersion int, secret, issuer, audience string, ttl time.Duration) (string, error) {
+	return generateJWT(userID, email, tokenVersion, PurposeMFAPending, secret, issuer, audience, ttl)
+}
+
+func generateJWT(userID uint, email string, tokenVersion int, purpose, secret, issuer, audience string, ttl time.Duration) (string, error) {
+	jti, err := GenerateRandomToken(jtiLength)
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
 	claims := &Claims{
-		UserID: userID,
-		Email:  email,
+		UserID:       userID,
+		Email:        email,
+		TokenVersion: tokenVersion,
+		Purpose:      purpose,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ID:        jti,
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(now),
 		},
 	}
 
@@ -34,7 +62,31 @@ func GenerateJWT(userID uint, email, secret, issuer, audience string) (string, e
 	return token.SignedString([]byte(secret))
 }
 
+// ValidateJWT validates an access token. Tokens issued for another purpose are rejected.
 func ValidateJWT(tokenString, secret, issuer, audience string) (*Claims, error) {
+	claims, err := parseJWT(tokenString, secret, issuer, audience)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Purpose != "" {
+		return nil, errors.New("invalid token purpose")
+	}
+	return claims, nil
+}
+
+// ValidateMFAToken validates a token issued by GenerateMFAToken.
+func ValidateMFAToken(tokenString, secret, issuer, audience string) (*Claims, error) {
+	claims, err := parseJWT(tokenString, secret, issuer, audience)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Purpose != PurposeMFAPending {
+		return nil, errors.New("invalid token purpose")
+	}
+	return claims, nil
+}
+
+func parseJWT(tokenString, secret, issuer, audience string) (*Claims, error) {
 	claims := &Claims{}
 
 	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
@@ -52,6 +104,10 @@ func ValidateJWT(tokenString, secret, issuer, audience string) (*Claims, error)
 		return nil, jwt.ErrSignatureInvalid
 	}
 
+	if claims.ID == "" {
+		return nil, errors.New("token is missing jti")
+	}
+
 	if issuer != "" && claims.Issuer != issuer {
 		return nil, errors.New("invalid token issuer")
 	}
@@ -9,10 +9,28 @@ import (
 	"strings"
 )
 
-const resetTokenLength = 32
+const (
+	resetTokenLength   = 32
+	refreshTokenLength = 32
+)
 
 // GenerateResetToken generates a random token, returns the signed token for clients and its hash for storage.
 func GenerateResetToken(secret string) (signedToken string, tokenHash string, err error) {
+	return GenerateSignedToken(secret)
+}
+
+// VerifyResetToken verifies the signature and returns the raw token part.
+func VerifyResetToken(signedToken, secret string) (string, error) {
+	return VerifySignedToken(signedToken, secret)
+}
+
+// HashResetToken hashes the raw token for safe persistence.
+func HashResetToken(rawToken string) string {
+	return hashToken(rawToken)
+}
+
+// GenerateSignedToken generates a random token signed with HMAC-SHA256, returns the signed token for clients and its hash for storage.
+func GenerateSignedToken(secret string) (signedToken string, tokenHash string, err error) {
 	rawToken, err := GenerateRandomToken(resetTokenLength)
 	if err != nil {
 		return "", "", err
@@ -24,8 +42,8 @@ func GenerateResetToken(secret string) (signedToken string, tokenHash string, er
 	return signedToken, tokenHash, nil
 }
 
-// VerifyResetToken verifies the signature and returns the raw token part.
-func VerifyResetToken(signedToken, secret string) (string, error) {
+// VerifySignedToken verifies the signature of a token from GenerateSignedToken and returns the raw token part.
+func VerifySignedToken(signedToken, secret string) (string, error) {
 	parts := strings.Split(signedToken, ".")
 	if len(parts) != 2 {
 		return "", errors.New("invalid token format")
@@ -39,11 +57,25 @@ func VerifyResetToken(signedToken, secret string) (string, error) {
 	return parts[0], nil
 }
 
-// HashResetToken hashes the raw token for safe persistence.
-func HashResetToken(rawToken string) string {
+// HashSignedToken hashes the raw token part for safe persistence.
+func HashSignedToken(rawToken string) string {
 	return hashToken(rawToken)
 }
 
+// GenerateRefreshToken returns an opaque refresh token for clients and its hash for storage.
+func GenerateRefreshToken() (token string, tokenHash string, err error) {
+	token, err = GenerateRandomToken(refreshTokenLength)
+	if err != nil {
+		return "", "", err
+	}
+	return token, hashToken(token), nil
+}
+
+// HashRefreshToken hashes a refresh token presented by a client for lookup.
+func HashRefreshToken(token string) string {
+	return hashToken(token)
+}
+
 // GenerateRandomToken returns a random hex-encoded string of the given byte length.
 func GenerateRandomToken(length int) (string, error) {
 	bytes := make([]byte, length)